@@ -0,0 +1,200 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultExecTimeout = 30 * time.Second
+	defaultExecTTL     = 5 * time.Minute
+)
+
+// AuthProvider provides the credentials used to authenticate requests to the Harness API.
+type AuthProvider interface {
+	// GetHeader returns the name and value of the header that authenticates a request.
+	GetHeader(ctx context.Context) (key string, value string, err error)
+}
+
+// TokenType describes how a token is sent to Harness.
+type TokenType string
+
+const (
+	// TokenTypeAPIKey sends the token in the x-api-key header (Harness PATs and SATs).
+	TokenTypeAPIKey TokenType = "api-key"
+	// TokenTypeBearer sends the token in the Authorization header (JWTs).
+	TokenTypeBearer TokenType = "bearer"
+)
+
+// header returns the header name and value for a token of this type.
+func (t TokenType) header(token string) (string, string, error) {
+	switch t {
+	case TokenTypeAPIKey, "":
+		return apiKeyHeader, token, nil
+	case TokenTypeBearer:
+		return authorizationHeader, "Bearer " + token, nil
+	default:
+		return "", "", fmt.Errorf("unknown token type: %s", t)
+	}
+}
+
+// APIKeyProvider authenticates requests with a static Harness API key.
+type APIKeyProvider struct {
+	apiKey string
+}
+
+// NewAPIKeyProvider creates an auth provider for a static API key.
+func NewAPIKeyProvider(apiKey string) *APIKeyProvider {
+	return &APIKeyProvider{apiKey: apiKey}
+}
+
+func (p *APIKeyProvider) GetHeader(_ context.Context) (string, string, error) {
+	if p.apiKey == "" {
+		return "", "", ErrNoCredentials
+	}
+	return TokenTypeAPIKey.header(p.apiKey)
+}
+
+// BearerTokenProvider authenticates requests with a static bearer token (JWT).
+type BearerTokenProvider struct {
+	token string
+}
+
+// NewBearerTokenProvider creates an auth provider for a static bearer token.
+func NewBearerTokenProvider(token string) *BearerTokenProvider {
+	return &BearerTokenProvider{token: token}
+}
+
+func (p *BearerTokenProvider) GetHeader(_ context.Context) (string, string, error) {
+	if p.token == "" {
+		return "", "", ErrNoCredentials
+	}
+	return TokenTypeBearer.header(p.token)
+}
+
+// FileTokenProvider authenticates requests with a token read from a file.
+// The file is read again whenever it changes, so tokens can be rotated
+// (e.g. by a mounted Kubernetes secret) without restarting the server.
+type FileTokenProvider struct {
+	path      string
+	tokenType TokenType
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+// NewFileTokenProvider creates an auth provider that reads its token from path.
+func NewFileTokenProvider(path string, tokenType TokenType) *FileTokenProvider {
+	return &FileTokenProvider{path: path, tokenType: tokenType}
+}
+
+func (p *FileTokenProvider) GetHeader(_ context.Context) (string, string, error) {
+	token, err := p.readToken()
+	if err != nil {
+		return "", "", err
+	}
+	return p.tokenType.header(token)
+}
+
+// readToken returns the cached token, re-reading the file if it was modified since the last read.
+func (p *FileTokenProvider) readToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat token file: %w", err)
+	}
+
+	if p.token != "" && info.ModTime().Equal(p.modTime) && info.Size() == p.size {
+		return p.token, nil
+	}
+
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", p.path)
+	}
+
+	p.token = token
+	p.modTime = info.ModTime()
+	p.size = info.Size()
+	return p.token, nil
+}
+
+// ExecTokenProvider authenticates requests with a token printed to stdout by a credential
+// helper command. The token is cached for a configurable TTL before the command is run again.
+type ExecTokenProvider struct {
+	command   string
+	args      []string
+	tokenType TokenType
+	ttl       time.Duration
+
+	mu        sync.Mutex
+	token     string
+	fetchedAt time.Time
+}
+
+// NewExecTokenProvider creates an auth provider that runs command with args to obtain a token.
+// A ttl of zero uses the default of 5 minutes.
+func NewExecTokenProvider(command string, args []string, tokenType TokenType, ttl time.Duration) *ExecTokenProvider {
+	if ttl <= 0 {
+		ttl = defaultExecTTL
+	}
+	return &ExecTokenProvider{
+		command:   command,
+		args:      args,
+		tokenType: tokenType,
+		ttl:       ttl,
+	}
+}
+
+func (p *ExecTokenProvider) GetHeader(ctx context.Context) (string, string, error) {
+	token, err := p.fetchToken(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	return p.tokenType.header(token)
+}
+
+// fetchToken returns the cached token, running the credential helper if it has expired.
+func (p *ExecTokenProvider) fetchToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Since(p.fetchedAt) < p.ttl {
+		return p.token, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultExecTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.command, p.args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("credential helper %s failed: %w: %s", p.command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	token := strings.TrimSpace(stdout.String())
+	if token == "" {
+		return "", fmt.Errorf("credential helper %s returned an empty token", p.command)
+	}
+
+	p.token = token
+	p.fetchedAt = time.Now()
+	return p.token, nil
+}
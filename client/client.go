@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -14,7 +15,6 @@ import (
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/harness/harness-mcp/client/dto"
-	"github.com/rs/zerolog/log"
 )
 
 var (
@@ -26,26 +26,44 @@ var (
 	defaultPageSize = 5
 	maxPageSize     = 20
 
-	apiKeyHeader = "x-api-key"
+	apiKeyHeader        = "x-api-key"
+	authorizationHeader = "Authorization"
 )
 
 var (
-	ErrBadRequest = fmt.Errorf("bad request")
-	ErrNotFound   = fmt.Errorf("not found")
-	ErrInternal   = fmt.Errorf("internal error")
+	ErrBadRequest      = fmt.Errorf("bad request")
+	ErrUnauthorized    = fmt.Errorf("unauthorized")
+	ErrForbidden       = fmt.Errorf("forbidden")
+	ErrNotFound        = fmt.Errorf("not found")
+	ErrConflict        = fmt.Errorf("conflict")
+	ErrTooManyRequests = fmt.Errorf("too many requests")
+	ErrInternal        = fmt.Errorf("internal error")
+
+	ErrNoCredentials = fmt.Errorf("no credentials available for request")
 )
 
 type Client struct {
 	client *http.Client // HTTP client used for communicating with the Harness API
 
+	// HTTP client used for streamed responses, which have no overall timeout
+	// and are bounded by the request context instead
+	streamClient *http.Client
+
 	// Base URL for API requests. Defaults to the public Harness API, but can be
 	// set to a domain endpoint to use with custom Harness installations
 	BaseURL *url.URL
 
-	// API key for authentication
-	// TODO: We can abstract out the auth provider
+	// Provides the credentials used to authenticate requests
+	AuthProvider AuthProvider
+
+	// API key for authentication, used when AuthProvider is not set
+	//
+	// Deprecated: set AuthProvider to an APIKeyProvider instead.
 	APIKey string
 
+	// Controls how failed requests are retried
+	RetryPolicy RetryPolicy
+
 	// Services used for talking to different Harness entities
 	Connectors   *ConnectorService
 	PullRequests *PullRequestService
@@ -66,14 +84,25 @@ func defaultHTTPClient() *http.Client {
 
 // NewWithToken creates a new client with the specified base URL and API token
 func NewWithToken(uri, apiKey string) (*Client, error) {
+	c, err := NewWithAuthProvider(uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.APIKey = apiKey
+	return c, nil
+}
+
+// NewWithAuthProvider creates a new client with the specified base URL and auth provider
+func NewWithAuthProvider(uri string, authProvider AuthProvider) (*Client, error) {
 	parsedURL, err := url.Parse(uri)
 	if err != nil {
 		return nil, err
 	}
 	c := &Client{
-		client:  defaultHTTPClient(),
-		BaseURL: parsedURL,
-		APIKey:  apiKey,
+		client:       defaultHTTPClient(),
+		BaseURL:      parsedURL,
+		AuthProvider: authProvider,
+		RetryPolicy:  DefaultRetryPolicy(),
 	}
 	c.initialize()
 	return c, nil
@@ -83,6 +112,9 @@ func (c *Client) initialize() error {
 	if c.client == nil {
 		c.client = defaultHTTPClient()
 	}
+	if c.streamClient == nil {
+		c.streamClient = &http.Client{Transport: c.client.Transport}
+	}
 	if c.BaseURL == nil {
 		baseURL, err := url.Parse(defaultBaseURL)
 		if err != nil {
@@ -102,6 +134,7 @@ func (c *Client) initialize() error {
 
 // Get is a simple helper that builds up the request URL, adding the path and parameters.
 // The response from the request is unmarshalled into the data parameter.
+// Failed requests are retried following the client's retry policy.
 func (c *Client) Get(
 	ctx context.Context,
 	path string,
@@ -109,56 +142,103 @@ func (c *Client) Get(
 	headers map[string]string,
 	response interface{},
 ) error {
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, appendPath(c.BaseURL.String(), path), nil)
-	if err != nil {
-		return fmt.Errorf("unable to create new http request : %w", err)
-	}
-
-	addQueryParams(httpReq, params)
-	for key, value := range headers {
-		httpReq.Header.Add(key, value)
-	}
-	// Execute the request
-	resp, err := c.Do(httpReq)
+	return c.send(ctx, http.MethodGet, path, queryValues(params), headers, nil, response, nil)
+}
 
-	// ensure the body is closed after we read (independent of status code or error)
-	if resp != nil && resp.Body != nil {
-		// Use function to satisfy the linter which complains about unhandled errors otherwise
-		defer func() { _ = resp.Body.Close() }()
-	}
+// GetValues is like Get, for query parameters that are repeated once per value.
+func (c *Client) GetValues(
+	ctx context.Context,
+	path string,
+	query url.Values,
+	headers map[string]string,
+	response interface{},
+) error {
+	return c.send(ctx, http.MethodGet, path, query, headers, nil, response, nil)
+}
 
+// Post is a simple helper that builds up the request URL, adding the path and parameters.
+// The response from the request is unmarshalled into the out parameter.
+func (c *Client) Post(
+	ctx context.Context,
+	path string,
+	params map[string]string,
+	body interface{},
+	out interface{},
+	b ...backoff.BackOff,
+) error {
+	bodyBytes, err := json.Marshal(body)
 	if err != nil {
-		return fmt.Errorf("request execution failed: %w", err)
+		return fmt.Errorf("failed to serialize body: %w", err)
 	}
 
-	// map the error from the status code
-	err = mapStatusCodeToError(resp.StatusCode)
-
-	// response output is optional
-	if response == nil || resp == nil || resp.Body == nil {
-		return err
-	}
+	return c.PostRaw(ctx, path, params, bytes.NewBuffer(bodyBytes), nil, out, b...)
+}
 
-	// Try to unmarshal the response
-	if err := unmarshalResponse(resp, response); err != nil {
-		// If we already have a status code error, wrap it with the unmarshal error
-		if statusErr := mapStatusCodeToError(resp.StatusCode); statusErr != nil {
-			return fmt.Errorf("%w: %v", statusErr, err)
-		}
-		return err
-	}
+// PostRaw is a simple helper that builds up the request URL, adding the path and parameters.
+// The response from the request is unmarshalled into the out parameter.
+// POST requests are not retried unless a backoff is passed in, since they may not be safe to repeat.
+func (c *Client) PostRaw(
+	ctx context.Context,
+	path string,
+	params map[string]string,
+	body io.Reader,
+	headers map[string]string,
+	out interface{},
+	b ...backoff.BackOff,
+) error {
+	return c.sendRaw(ctx, http.MethodPost, path, queryValues(params), body, headers, out, b...)
+}
 
-	// Return any status code error if present
+// Put is a simple helper that builds up the request URL, adding the path and parameters.
+// The response from the request is unmarshalled into the out parameter.
+func (c *Client) Put(
+	ctx context.Context,
+	path string,
+	params map[string]string,
+	body interface{},
+	out interface{},
+	b ...backoff.BackOff,
+) error {
+	bodyBytes, err := json.Marshal(body)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to serialize body: %w", err)
 	}
 
-	return err
+	return c.PutRaw(ctx, path, params, bytes.NewBuffer(bodyBytes), nil, out, b...)
 }
 
-// Post is a simple helper that builds up the request URL, adding the path and parameters.
+// PutRaw is a simple helper that builds up the request URL, adding the path and parameters.
 // The response from the request is unmarshalled into the out parameter.
-func (c *Client) Post(
+// PUT requests are not retried unless a backoff is passed in, since Harness does not guarantee they are safe to repeat.
+func (c *Client) PutRaw(
+	ctx context.Context,
+	path string,
+	params map[string]string,
+	body io.Reader,
+	headers map[string]string,
+	out interface{},
+	b ...backoff.BackOff,
+) error {
+	return c.sendRaw(ctx, http.MethodPut, path, queryValues(params), body, headers, out, b...)
+}
+
+// PutRawValues is like PutRaw, for query parameters that are repeated once per value.
+func (c *Client) PutRawValues(
+	ctx context.Context,
+	path string,
+	query url.Values,
+	body io.Reader,
+	headers map[string]string,
+	out interface{},
+	b ...backoff.BackOff,
+) error {
+	return c.sendRaw(ctx, http.MethodPut, path, query, body, headers, out, b...)
+}
+
+// Patch is a simple helper that builds up the request URL, adding the path and parameters.
+// The response from the request is unmarshalled into the out parameter.
+// PATCH requests are not retried unless a backoff is passed in, since Harness does not guarantee they are safe to repeat.
+func (c *Client) Patch(
 	ctx context.Context,
 	path string,
 	params map[string]string,
@@ -171,90 +251,282 @@ func (c *Client) Post(
 		return fmt.Errorf("failed to serialize body: %w", err)
 	}
 
-	return c.PostRaw(ctx, path, params, bytes.NewBuffer(bodyBytes), nil, out, b...)
+	return c.sendRaw(ctx, http.MethodPatch, path, queryValues(params), bytes.NewBuffer(bodyBytes), nil, out, b...)
 }
 
-// PostRaw is a simple helper that builds up the request URL, adding the path and parameters.
-// The response from the request is unmarshalled into the out parameter.
-func (c *Client) PostRaw(
+// Delete is a simple helper that builds up the request URL, adding the path and parameters.
+// The response from the request is unmarshalled into the out parameter, unless it is nil.
+// DELETE requests are not retried unless a backoff is passed in, since Harness does not guarantee they are safe to repeat.
+func (c *Client) Delete(
 	ctx context.Context,
 	path string,
 	params map[string]string,
+	out interface{},
+	b ...backoff.BackOff,
+) error {
+	var bo backoff.BackOff
+	if len(b) > 0 {
+		bo = b[0]
+	}
+
+	return c.send(ctx, http.MethodDelete, path, queryValues(params), nil, nil, out, bo)
+}
+
+// sendRaw reads the body and sends it as JSON unless the headers set another content type.
+// A nil body sends a request without a body.
+func (c *Client) sendRaw(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
 	body io.Reader,
 	headers map[string]string,
 	out interface{},
 	b ...backoff.BackOff,
 ) error {
-	var retryCount int
-
-	operation := func() error {
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, appendPath(c.BaseURL.String(), path), body)
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		bodyBytes, err = io.ReadAll(body)
 		if err != nil {
-			return backoff.Permanent(fmt.Errorf("unable to create HTTP request: %w", err))
+			return fmt.Errorf("failed to read body: %w", err)
 		}
+	}
 
-		req.Header.Set("Content-Type", "application/json")
-		// Add custom headers from the headers map
-		for key, value := range headers {
-			req.Header.Add(key, value)
-		}
-		addQueryParams(req, params)
+	allHeaders := map[string]string{}
+	if body != nil {
+		allHeaders["Content-Type"] = "application/json"
+	}
+	for key, value := range headers {
+		allHeaders[key] = value
+	}
 
-		resp, err := c.Do(req)
+	var bo backoff.BackOff
+	if len(b) > 0 {
+		bo = b[0]
+	}
 
-		if resp != nil && resp.Body != nil {
-			defer resp.Body.Close()
-		}
+	return c.send(ctx, method, path, query, allHeaders, bodyBytes, out, bo)
+}
+
+// send executes a request and unmarshals the response into out, retrying transient failures.
+// The backoff decides the wait between attempts, see retry.
+func (c *Client) send(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	headers map[string]string,
+	body []byte,
+	out interface{},
+	b backoff.BackOff,
+) error {
+	return c.retry(ctx, method, path, b, func() error {
+		return c.attempt(ctx, method, path, query, headers, body, out)
+	})
+}
 
-		if err != nil || resp == nil {
-			return fmt.Errorf("request failed: %w", err)
+// GetStream is like Get but returns the response body unread, for responses that are too large
+// to buffer or are consumed as a stream. Establishing the response is retried following the
+// client's retry policy, reading the body is only bounded by ctx. The caller must close the returned body.
+func (c *Client) GetStream(
+	ctx context.Context,
+	path string,
+	params map[string]string,
+	headers map[string]string,
+) (io.ReadCloser, error) {
+	var body io.ReadCloser
+	err := c.retry(ctx, http.MethodGet, path, nil, func() error {
+		resp, err := c.open(ctx, c.streamClient, http.MethodGet, path, queryValues(params), headers, nil)
+		if err != nil {
+			return err
 		}
+		body = resp.Body
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
 
-		if isRetryable(resp.StatusCode) {
-			return fmt.Errorf("retryable status code: %d", resp.StatusCode)
+// retry runs fn until it succeeds, fails with an error that cannot be retried, or the backoff
+// gives up. If b is nil, idempotent methods use the client's retry policy and other methods
+// are attempted once. A Retry-After header sent by the server takes precedence over a shorter
+// backoff, as long as it fits in the policy's time budget.
+func (c *Client) retry(ctx context.Context, method string, path string, b backoff.BackOff, fn func() error) error {
+	if b == nil {
+		if isIdempotent(method) {
+			b = c.RetryPolicy.NewBackOff()
+		} else {
+			b = &backoff.StopBackOff{}
 		}
+	}
+
+	start := time.Now()
+	for retryCount := 0; ; retryCount++ {
+		err := fn()
 
-		if statusErr := mapStatusCodeToError(resp.StatusCode); statusErr != nil {
-			return backoff.Permanent(statusErr)
+		var retryErr *retryableError
+		if !errors.As(err, &retryErr) {
+			return err
 		}
 
-		if out != nil && resp.Body != nil {
-			if err := unmarshalResponse(resp, out); err != nil {
-				return fmt.Errorf("unmarshal error: %w", err)
+		next := b.NextBackOff()
+		if next == backoff.Stop {
+			if retryCount == 0 {
+				return retryErr.err
 			}
+			return fmt.Errorf("request failed after %d retries: %w", retryCount, retryErr.err)
 		}
+		if retryErr.retryAfter > next {
+			next = retryErr.retryAfter
+		}
+		if budget := c.RetryPolicy.MaxElapsedTime; budget > 0 && time.Since(start)+next > budget {
+			return fmt.Errorf("request failed after %d retries, retry budget of %s exhausted: %w", retryCount, budget, retryErr.err)
+		}
+
+		slog.Warn("Retrying request due to error",
+			"method", method,
+			"path", path,
+			"retry_count", retryCount+1,
+			"next_retry_in", next,
+			"error", retryErr.err,
+		)
+
+		timer := time.NewTimer(next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("request cancelled while waiting to retry: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
 
+// attempt executes a single request and unmarshals the response into out.
+// Failures that can be retried are returned as a *retryableError.
+func (c *Client) attempt(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	headers map[string]string,
+	body []byte,
+	out interface{},
+) error {
+	resp, err := c.open(ctx, c.client, method, path, query, headers, body)
+	if err != nil {
+		return err
+	}
+	// Use function to satisfy the linter which complains about unhandled errors otherwise
+	defer func() { _ = resp.Body.Close() }()
+
+	// response output is optional
+	if out == nil {
 		return nil
 	}
 
-	notify := func(err error, next time.Duration) {
-		retryCount++
-		log.Warn().
-			Int("retry_count", retryCount).
-			Dur("next_retry_in", next).
-			Err(err).
-			Msg("Retrying request due to error")
+	return unmarshalResponse(resp, out)
+}
+
+// open executes a single request and returns the response if it has a success status code,
+// leaving its body for the caller to read and close.
+// Failures that can be retried are returned as a *retryableError.
+func (c *Client) open(
+	ctx context.Context,
+	hc *http.Client,
+	method string,
+	path string,
+	query url.Values,
+	headers map[string]string,
+	body []byte,
+) (*http.Response, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
 	}
 
-	if len(b) > 0 {
-		if err := backoff.RetryNotify(operation, b[0], notify); err != nil {
-			return fmt.Errorf("request failed after %d retries: %w", retryCount, err)
+	req, err := http.NewRequestWithContext(ctx, method, appendPath(c.BaseURL.String(), path), bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new http request : %w", err)
+	}
+
+	addQueryParams(req, query)
+	for key, value := range headers {
+		req.Header.Add(key, value)
+	}
+
+	// Execute the request
+	resp, err := c.do(hc, req)
+	if err != nil {
+		if resp != nil && resp.Body != nil {
+			_ = resp.Body.Close()
 		}
-	} else {
-		if err := operation(); err != nil {
-			return err
+		err = fmt.Errorf("request execution failed: %w", err)
+		// transport errors are transient unless the caller gave up
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) && ctx.Err() == nil {
+			return nil, &retryableError{err: err}
 		}
+		return nil, err
 	}
 
-	return nil
+	if isRetryable(resp.StatusCode) {
+		defer func() { _ = resp.Body.Close() }()
+		return nil, &retryableError{
+			err:        newAPIError(resp),
+			retryAfter: parseRetryAfter(resp),
+		}
+	}
+
+	if resp.StatusCode >= 300 {
+		defer func() { _ = resp.Body.Close() }()
+		return nil, newAPIError(resp)
+	}
+
+	return resp, nil
 }
 
 // Do is a wrapper of http.Client.Do that injects the auth header in the request.
 func (c *Client) Do(r *http.Request) (*http.Response, error) {
+	return c.do(c.client, r)
+}
+
+func (c *Client) do(hc *http.Client, r *http.Request) (*http.Response, error) {
 	slog.Debug("Request", "method", r.Method, "url", r.URL.String())
-	r.Header.Add(apiKeyHeader, c.APIKey)
+	if err := c.setAuthHeader(r); err != nil {
+		return nil, err
+	}
 
-	return c.client.Do(r)
+	return hc.Do(r)
+}
+
+// setAuthHeader adds the credentials for the request. Credentials found in the request
+// context (set per incoming MCP request) take precedence over the client's auth provider,
+// so that a single client can act on behalf of many users.
+func (c *Client) setAuthHeader(r *http.Request) error {
+	if apiKey, ok := apiKeyFromContext(r.Context()); ok {
+		r.Header.Set(apiKeyHeader, apiKey)
+		return nil
+	}
+	if bearerToken, ok := bearerTokenFromContext(r.Context()); ok {
+		r.Header.Set(authorizationHeader, "Bearer "+bearerToken)
+		return nil
+	}
+	authProvider := c.AuthProvider
+	if authProvider == nil && c.APIKey != "" {
+		authProvider = NewAPIKeyProvider(c.APIKey)
+	}
+	if authProvider == nil {
+		return ErrNoCredentials
+	}
+	key, value, err := authProvider.GetHeader(r.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get credentials: %w", err)
+	}
+	r.Header.Set(key, value)
+	return nil
 }
 
 // appendPath appends the provided path to the uri
@@ -267,6 +539,15 @@ func appendPath(uri string, path string) string {
 	return strings.TrimRight(uri, "/") + "/" + strings.TrimLeft(path, "/")
 }
 
+// escapePath escapes each segment of a path, keeping the slashes between them.
+func escapePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
 // nolint:godot
 // unmarshalResponse reads the response body and if there are no errors marshall's it into data.
 func unmarshalResponse(resp *http.Response, data interface{}) error {
@@ -279,15 +560,6 @@ func unmarshalResponse(resp *http.Response, data interface{}) error {
 		return fmt.Errorf("error reading response body : %w", err)
 	}
 
-	// For non-success status codes, try to unmarshal as an error response first
-	if resp.StatusCode >= 400 {
-		var errResp dto.ErrorResponse
-		if jsonErr := json.Unmarshal(body, &errResp); jsonErr == nil && (errResp.Code != "" || errResp.Message != "") {
-			return fmt.Errorf("API error: %s", errResp.Message)
-		}
-		// If we couldn't parse it as an error response, continue with normal unmarshaling
-	}
-
 	err = json.Unmarshal(body, data)
 	if err != nil {
 		return fmt.Errorf("error deserializing response body : %w - original response: %s", err, string(body))
@@ -307,8 +579,16 @@ func mapStatusCodeToError(statusCode int) error {
 		return ErrInternal
 	case statusCode >= 500:
 		return fmt.Errorf("received server side error status code %d", statusCode)
+	case statusCode == 429:
+		return ErrTooManyRequests
+	case statusCode == 409:
+		return ErrConflict
 	case statusCode == 404:
 		return ErrNotFound
+	case statusCode == 403:
+		return ErrForbidden
+	case statusCode == 401:
+		return ErrUnauthorized
 	case statusCode == 400:
 		return ErrBadRequest
 	case statusCode >= 400:
@@ -321,17 +601,29 @@ func mapStatusCodeToError(statusCode int) error {
 	}
 }
 
-// addQueryParams if the params map is not empty, it adds each key/value pair to
+// queryValues converts params to query values. Comma-separated values are sent
+// as a repeated parameter, one per value.
+func queryValues(params map[string]string) url.Values {
+	query := url.Values{}
+	for key, value := range params {
+		for _, value := range strings.Split(value, ",") {
+			query.Add(key, value)
+		}
+	}
+	return query
+}
+
+// addQueryParams if the query is not empty, it adds each key/value pair to
 // the request URL.
-func addQueryParams(req *http.Request, params map[string]string) {
-	if len(params) == 0 {
+func addQueryParams(req *http.Request, query url.Values) {
+	if len(query) == 0 {
 		return
 	}
 
 	q := req.URL.Query()
 
-	for key, value := range params {
-		for _, value := range strings.Split(value, ",") {
+	for key, values := range query {
+		for _, value := range values {
 			q.Add(key, value)
 		}
 	}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+type contextKey string
+
+const (
+	apiKeyContextKey      contextKey = "apiKey"
+	bearerTokenContextKey contextKey = "bearerToken"
+)
+
+// WithAPIKey returns a copy of ctx carrying an API key. Requests made with the
+// returned context are authenticated with this key instead of the client's own.
+func WithAPIKey(ctx context.Context, apiKey string) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey, apiKey)
+}
+
+// WithBearerToken returns a copy of ctx carrying a bearer token. Requests made with the
+// returned context are authenticated with this token instead of the client's own API key.
+func WithBearerToken(ctx context.Context, bearerToken string) context.Context {
+	return context.WithValue(ctx, bearerTokenContextKey, bearerToken)
+}
+
+// apiKeyFromContext returns the API key stored in ctx, if any.
+func apiKeyFromContext(ctx context.Context) (string, bool) {
+	apiKey, ok := ctx.Value(apiKeyContextKey).(string)
+	return apiKey, ok && apiKey != ""
+}
+
+// bearerTokenFromContext returns the bearer token stored in ctx, if any.
+func bearerTokenFromContext(ctx context.Context) (string, bool) {
+	bearerToken, ok := ctx.Value(bearerTokenContextKey).(string)
+	return bearerToken, ok && bearerToken != ""
+}
+
+// CredentialsID returns an opaque identifier of the credentials carried by ctx, or an empty string if
+// requests made with ctx are authenticated by the client's auth provider. It tells callers apart
+// without keeping their credentials.
+func CredentialsID(ctx context.Context) string {
+	var credentials string
+	if apiKey, ok := apiKeyFromContext(ctx); ok {
+		credentials = "api-key:" + apiKey
+	} else if bearerToken, ok := bearerTokenFromContext(ctx); ok {
+		credentials = "bearer:" + bearerToken
+	} else {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(credentials))
+	return hex.EncodeToString(sum[:])
+}
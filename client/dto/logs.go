@@ -10,3 +10,12 @@ type LogDownloadResponse struct {
 	Status  string    `json:"status"`
 	Expires time.Time `json:"expires"`
 }
+
+// LogLine represents a single line of a step log as stored by the log service
+type LogLine struct {
+	Level string            `json:"level,omitempty"`
+	Pos   int               `json:"pos,omitempty"`
+	Out   string            `json:"out"`
+	Time  string            `json:"time,omitempty"`
+	Args  map[string]string `json:"args,omitempty"`
+}
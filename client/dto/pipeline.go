@@ -132,6 +132,134 @@ type PipelineExecutionResponse struct {
 	PipelineExecutionSummary PipelineExecution `json:"pipelineExecutionSummary,omitempty"`
 }
 
+// PipelineExecutionGraphResponse represents the execution details response including the execution graph
+type PipelineExecutionGraphResponse struct {
+	PipelineExecutionSummary PipelineExecutionLayout `json:"pipelineExecutionSummary,omitempty"`
+	ExecutionGraph           ExecutionGraph          `json:"executionGraph,omitempty"`
+}
+
+// PipelineExecutionLayout represents a pipeline execution along with the layout of its stages
+type PipelineExecutionLayout struct {
+	PipelineExecution
+	StartingNodeID string                `json:"startingNodeId,omitempty"`
+	LayoutNodeMap  map[string]LayoutNode `json:"layoutNodeMap,omitempty"`
+}
+
+// LayoutNode represents a stage (or a group of parallel stages) in the pipeline layout
+type LayoutNode struct {
+	NodeType        string                `json:"nodeType,omitempty"`
+	NodeGroup       string                `json:"nodeGroup,omitempty"`
+	NodeIdentifier  string                `json:"nodeIdentifier,omitempty"`
+	Name            string                `json:"name,omitempty"`
+	NodeUUID        string                `json:"nodeUuid,omitempty"`
+	NodeExecutionID string                `json:"nodeExecutionId,omitempty"`
+	Status          string                `json:"status,omitempty"`
+	Module          string                `json:"module,omitempty"`
+	StartTs         int64                 `json:"startTs,omitempty"`
+	EndTs           int64                 `json:"endTs,omitempty"`
+	FailureInfo     LayoutNodeFailureInfo `json:"failureInfo,omitempty"`
+	EdgeLayoutList  EdgeLayoutList        `json:"edgeLayoutList,omitempty"`
+}
+
+// LayoutNodeFailureInfo represents the failure information of a stage
+type LayoutNodeFailureInfo struct {
+	Message string `json:"message,omitempty"`
+}
+
+// EdgeLayoutList represents the edges from a layout node to its children and the nodes after it
+type EdgeLayoutList struct {
+	CurrentNodeChildren []string `json:"currentNodeChildren,omitempty"`
+	NextIDs             []string `json:"nextIds,omitempty"`
+}
+
+// ExecutionGraph represents the graph of nodes (stages, steps, step groups) of an execution
+type ExecutionGraph struct {
+	RootNodeID           string                            `json:"rootNodeId,omitempty"`
+	NodeMap              map[string]ExecutionNode          `json:"nodeMap,omitempty"`
+	NodeAdjacencyListMap map[string]ExecutionNodeAdjacency `json:"nodeAdjacencyListMap,omitempty"`
+}
+
+// ExecutionNode represents a node of the execution graph
+type ExecutionNode struct {
+	UUID                string                   `json:"uuid,omitempty"`
+	SetupID             string                   `json:"setupId,omitempty"`
+	Name                string                   `json:"name,omitempty"`
+	Identifier          string                   `json:"identifier,omitempty"`
+	BaseFqn             string                   `json:"baseFqn,omitempty"`
+	StepType            string                   `json:"stepType,omitempty"`
+	Status              string                   `json:"status,omitempty"`
+	StartTs             int64                    `json:"startTs,omitempty"`
+	EndTs               int64                    `json:"endTs,omitempty"`
+	FailureInfo         ExecutionNodeFailureInfo `json:"failureInfo,omitempty"`
+	LogBaseKey          string                   `json:"logBaseKey,omitempty"`
+	ExecutableResponses []ExecutableResponse     `json:"executableResponses,omitempty"`
+}
+
+// ExecutionNodeFailureInfo represents the failure information of a node
+type ExecutionNodeFailureInfo struct {
+	Message          string                     `json:"message,omitempty"`
+	FailureTypeList  []string                   `json:"failureTypeList,omitempty"`
+	ResponseMessages []ExecutionResponseMessage `json:"responseMessages,omitempty"`
+}
+
+// ExecutableResponse represents how a node was executed, it carries the keys of the node's logs
+type ExecutableResponse struct {
+	Async     *ExecutableLogKeys `json:"async,omitempty"`
+	Sync      *ExecutableLogKeys `json:"sync,omitempty"`
+	Task      *ExecutableLogKeys `json:"task,omitempty"`
+	TaskChain *ExecutableLogKeys `json:"taskChain,omitempty"`
+}
+
+// ExecutableLogKeys represents the log keys of an executable response
+type ExecutableLogKeys struct {
+	LogKeys []string `json:"logKeys,omitempty"`
+	Units   []string `json:"units,omitempty"`
+}
+
+// ExecutionNodeAdjacency represents the edges from an execution node
+type ExecutionNodeAdjacency struct {
+	Children []string `json:"children,omitempty"`
+	NextIDs  []string `json:"nextIds,omitempty"`
+}
+
+// ExecutionTree represents a compact view of an execution graph: stages and their steps
+type ExecutionTree struct {
+	PlanExecutionID    string      `json:"planExecutionId,omitempty"`
+	PipelineIdentifier string      `json:"pipelineIdentifier,omitempty"`
+	Status             string      `json:"status,omitempty"`
+	StartTs            int64       `json:"startTs,omitempty"`
+	EndTs              int64       `json:"endTs,omitempty"`
+	Stages             []StageNode `json:"stages,omitempty"`
+}
+
+// StageNode represents a stage of an execution tree
+type StageNode struct {
+	Identifier      string     `json:"identifier,omitempty"`
+	Name            string     `json:"name,omitempty"`
+	NodeExecutionID string     `json:"nodeExecutionId,omitempty"`
+	Status          string     `json:"status,omitempty"`
+	StartTs         int64      `json:"startTs,omitempty"`
+	EndTs           int64      `json:"endTs,omitempty"`
+	DurationMs      int64      `json:"durationMs,omitempty"`
+	FailureMessage  string     `json:"failureMessage,omitempty"`
+	Steps           []StepNode `json:"steps,omitempty"`
+}
+
+// StepNode represents a step of an execution tree
+type StepNode struct {
+	Identifier      string   `json:"identifier,omitempty"`
+	Name            string   `json:"name,omitempty"`
+	NodeExecutionID string   `json:"nodeExecutionId,omitempty"`
+	StepType        string   `json:"stepType,omitempty"`
+	Status          string   `json:"status,omitempty"`
+	StartTs         int64    `json:"startTs,omitempty"`
+	EndTs           int64    `json:"endTs,omitempty"`
+	DurationMs      int64    `json:"durationMs,omitempty"`
+	FailureMessage  string   `json:"failureMessage,omitempty"`
+	FailureTypes    []string `json:"failureTypes,omitempty"`
+	LogKeys         []string `json:"logKeys,omitempty"`
+}
+
 // PipelineExecution represents a pipeline execution
 type PipelineExecution struct {
 	PipelineIdentifier         string               `json:"pipelineIdentifier,omitempty"`
@@ -178,3 +306,96 @@ type User struct {
 	UserName  string `json:"userName,omitempty"`
 	CreatedAt int64  `json:"createdAt,omitempty"`
 }
+
+// PipelineRunOptions represents the options for running a pipeline
+type PipelineRunOptions struct {
+	// Runtime input YAML for the pipeline, merged on top of the input sets if both are set
+	RuntimeInputYAML string `json:"runtimeInputYaml,omitempty"`
+	// Identifiers of input sets to run the pipeline with
+	InputSetReferences []string `json:"inputSetReferences,omitempty"`
+	// Git branch to fetch the pipeline from, for pipelines stored remotely in git
+	Branch string `json:"branch,omitempty"`
+	// Git repository to fetch the pipeline from, for pipelines stored remotely in git
+	RepoIdentifier string `json:"repoIdentifier,omitempty"`
+}
+
+// PipelineInputSetRunRequest represents the request body for running a pipeline with input sets
+type PipelineInputSetRunRequest struct {
+	InputSetReferences []string `json:"inputSetReferences"`
+	LastYamlToMerge    string   `json:"lastYamlToMerge,omitempty"`
+}
+
+// PipelineRunResponse represents the response from running a pipeline
+type PipelineRunResponse struct {
+	PlanExecution PlanExecution `json:"planExecution,omitempty"`
+}
+
+// PlanExecution represents a plan execution started by a pipeline run
+type PlanExecution struct {
+	UUID      string `json:"uuid,omitempty"`
+	PlanID    string `json:"planId,omitempty"`
+	Status    string `json:"status,omitempty"`
+	StartTs   int64  `json:"startTs,omitempty"`
+	CreatedAt int64  `json:"createdAt,omitempty"`
+}
+
+// Interrupt types for controlling a running pipeline execution
+const (
+	InterruptTypeAbort  = "AbortAll"
+	InterruptTypePause  = "Pause"
+	InterruptTypeResume = "Resume"
+)
+
+// InterruptResponse represents the response from interrupting a pipeline execution
+type InterruptResponse struct {
+	ID              string `json:"id,omitempty"`
+	InterruptType   string `json:"interruptType,omitempty"`
+	PlanExecutionID string `json:"planExecutionId,omitempty"`
+}
+
+// RetryInfo represents the stages of a pipeline execution that can be retried
+type RetryInfo struct {
+	ErrorMessage string       `json:"errorMessage,omitempty"`
+	IsResumable  bool         `json:"isResumable,omitempty"`
+	Groups       []RetryGroup `json:"groups,omitempty"`
+}
+
+// RetryGroup represents a group of stages that run in parallel
+type RetryGroup struct {
+	Info []RetryStageInfo `json:"info,omitempty"`
+}
+
+// RetryStageInfo represents a stage that can be retried
+type RetryStageInfo struct {
+	Name       string `json:"name,omitempty"`
+	Identifier string `json:"identifier,omitempty"`
+	Status     string `json:"status,omitempty"`
+	CreatedAt  int64  `json:"createdAt,omitempty"`
+	ParentID   string `json:"parentId,omitempty"`
+	NextID     string `json:"nextId,omitempty"`
+}
+
+// RetryHistory represents the previous retries of a pipeline execution
+type RetryHistory struct {
+	ErrorMessage      string               `json:"errorMessage,omitempty"`
+	LatestExecutionID string               `json:"latestExecutionId,omitempty"`
+	ExecutionInfos    []RetryExecutionInfo `json:"executionInfos,omitempty"`
+}
+
+// RetryExecutionInfo represents one execution in the retry history
+type RetryExecutionInfo struct {
+	UUID    string `json:"uuid,omitempty"`
+	Status  string `json:"status,omitempty"`
+	StartTs int64  `json:"startTs,omitempty"`
+	EndTs   int64  `json:"endTs,omitempty"`
+}
+
+// PipelineRetryOptions represents the options for retrying a pipeline execution
+type PipelineRetryOptions struct {
+	// Identifiers of the stages to retry from
+	RetryStages []string `json:"retryStages,omitempty"`
+	// Whether all the stages of a parallel group are retried, or only the failed ones
+	RunAllStages bool `json:"runAllStages,omitempty"`
+	// Runtime input YAML for the retried execution
+	RuntimeInputYAML string `json:"runtimeInputYaml,omitempty"`
+}
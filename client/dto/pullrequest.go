@@ -92,6 +92,26 @@ type CreatePullRequest struct {
 	IsDraft      bool   `json:"is_draft,omitempty"`
 }
 
+// UpdatePullRequest represents the request body for updating a pull request, fields that are nil are left unchanged
+type UpdatePullRequest struct {
+	Title        *string `json:"title,omitempty"`
+	Description  *string `json:"description,omitempty"`
+	TargetBranch *string `json:"target_branch,omitempty"`
+}
+
+// Pull request states
+const (
+	PullRequestStateOpen   = "open"
+	PullRequestStateClosed = "closed"
+	PullRequestStateMerged = "merged"
+)
+
+// PullRequestState represents the request body for closing or reopening a pull request, or changing whether it is a draft
+type PullRequestState struct {
+	State   string `json:"state"`
+	IsDraft bool   `json:"is_draft"`
+}
+
 // PullRequestCheckPayload represents the payload for a pull request check
 type PullRequestCheckPayload struct {
 	Data    interface{} `json:"data"`
@@ -158,3 +178,200 @@ type PullRequestOptions struct {
 	AuthorID      int      `json:"author_id,omitempty"`
 	IncludeChecks bool     `json:"include_checks,omitempty"`
 }
+
+// Methods of merging a pull request
+const (
+	MergeMethodMerge       = "merge"
+	MergeMethodSquash      = "squash"
+	MergeMethodRebase      = "rebase"
+	MergeMethodFastForward = "fast-forward"
+)
+
+// MergePullRequest represents the request body for merging a pull request
+type MergePullRequest struct {
+	Method string `json:"method"`
+	// Commit the source branch must be at, so that commits pushed after it was checked are not merged
+	SourceSha string `json:"source_sha"`
+	// Title and message of the merge or squash commit, generated if empty
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Whether the source branch is deleted once merged
+	DeleteSourceBranch bool `json:"delete_source_branch,omitempty"`
+	// Only check whether the pull request can be merged, and whether the rules allow it
+	DryRun      bool `json:"dry_run,omitempty"`
+	DryRunRules bool `json:"dry_run_rules,omitempty"`
+}
+
+// MergeResponse represents the response from merging a pull request, or checking whether it can be merged
+type MergeResponse struct {
+	SHA            string           `json:"sha,omitempty"`
+	BranchDeleted  bool             `json:"branch_deleted,omitempty"`
+	RuleViolations []RuleViolations `json:"rule_violations,omitempty"`
+
+	// Returned by dry runs only
+	DryRun                              bool     `json:"dry_run,omitempty"`
+	DryRunRules                         bool     `json:"dry_run_rules,omitempty"`
+	Mergeable                           bool     `json:"mergeable,omitempty"`
+	ConflictFiles                       []string `json:"conflict_files,omitempty"`
+	AllowedMethods                      []string `json:"allowed_methods,omitempty"`
+	RequiresCodeOwnersApproval          bool     `json:"requires_code_owners_approval,omitempty"`
+	RequiresCodeOwnersApprovalLatest    bool     `json:"requires_code_owners_approval_latest,omitempty"`
+	RequiresCommentResolution           bool     `json:"requires_comment_resolution,omitempty"`
+	RequiresNoChangeRequests            bool     `json:"requires_no_change_requests,omitempty"`
+	MinimumRequiredApprovalsCount       int      `json:"minimum_required_approvals_count,omitempty"`
+	MinimumRequiredApprovalsCountLatest int      `json:"minimum_required_approvals_count_latest,omitempty"`
+}
+
+// RuleViolations represents the violations of a branch rule by an operation on a pull request
+type RuleViolations struct {
+	Rule       PullRequestRule `json:"rule,omitempty"`
+	Bypassable bool            `json:"bypassable,omitempty"`
+	Bypassed   bool            `json:"bypassed,omitempty"`
+	Violations []RuleViolation `json:"violations,omitempty"`
+}
+
+// RuleViolation represents a single violation of a branch rule
+type RuleViolation struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Kinds of pull request activities
+const (
+	PullRequestActivityKindComment       = "comment"
+	PullRequestActivityKindChangeComment = "change-comment"
+	PullRequestActivityKindSystem        = "system"
+)
+
+// Statuses of pull request comments
+const (
+	PullRequestCommentStatusActive   = "active"
+	PullRequestCommentStatusResolved = "resolved"
+)
+
+// PullRequestActivity represents an activity of a pull request: a comment, a comment on code,
+// or a change of the pull request like a state change or a review
+type PullRequestActivity struct {
+	ID       int    `json:"id,omitempty"`
+	Created  int64  `json:"created,omitempty"`
+	Updated  int64  `json:"updated,omitempty"`
+	Edited   int64  `json:"edited,omitempty"`
+	Deleted  int64  `json:"deleted,omitempty"`
+	ParentID int    `json:"parent_id,omitempty"`
+	Order    int    `json:"order,omitempty"`
+	SubOrder int    `json:"sub_order,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+	Text     string `json:"text,omitempty"`
+	// Details of the activity, which depend on its type
+	Payload     map[string]interface{}  `json:"payload,omitempty"`
+	Resolved    int64                   `json:"resolved,omitempty"`
+	Resolver    *PullRequestAuthor      `json:"resolver,omitempty"`
+	Author      PullRequestAuthor       `json:"author,omitempty"`
+	CodeComment *PullRequestCodeComment `json:"code_comment,omitempty"`
+}
+
+// PullRequestCodeComment represents where a comment on code is anchored
+type PullRequestCodeComment struct {
+	// Whether the lines were changed since the comment was made
+	Outdated     bool   `json:"outdated,omitempty"`
+	MergeBaseSha string `json:"merge_base_sha,omitempty"`
+	SourceSha    string `json:"source_sha,omitempty"`
+	Path         string `json:"path,omitempty"`
+	LineNew      int    `json:"line_new,omitempty"`
+	SpanNew      int    `json:"span_new,omitempty"`
+	LineOld      int    `json:"line_old,omitempty"`
+	SpanOld      int    `json:"span_old,omitempty"`
+}
+
+// PullRequestActivityOptions represents the options for listing pull request activities
+type PullRequestActivityOptions struct {
+	Kind   string `json:"kind,omitempty"`
+	After  int64  `json:"after,omitempty"`
+	Before int64  `json:"before,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+// CreatePullRequestComment represents the request body for commenting on a pull request.
+// Comments with a path are anchored on lines of the file, replies have the ID of the comment they reply to.
+type CreatePullRequestComment struct {
+	Text     string `json:"text"`
+	ParentID int    `json:"parent_id,omitempty"`
+	// Commits the lines are numbered in: the source commit for new lines, the merge base for old lines
+	SourceCommitSha string `json:"source_commit_sha,omitempty"`
+	TargetCommitSha string `json:"target_commit_sha,omitempty"`
+	Path            string `json:"path,omitempty"`
+	LineStart       int    `json:"line_start,omitempty"`
+	LineStartNew    bool   `json:"line_start_new,omitempty"`
+	LineEnd         int    `json:"line_end,omitempty"`
+	LineEndNew      bool   `json:"line_end_new,omitempty"`
+}
+
+// PullRequestCommentStatus represents the request body for resolving a pull request comment, or reopening it
+type PullRequestCommentStatus struct {
+	Status string `json:"status"`
+}
+
+// Review decisions of pull request reviewers
+const (
+	ReviewDecisionPending   = "pending"
+	ReviewDecisionReviewed  = "reviewed"
+	ReviewDecisionApproved  = "approved"
+	ReviewDecisionChangeReq = "changereq"
+)
+
+// PullRequestReviewer represents a reviewer of a pull request and their latest review decision
+type PullRequestReviewer struct {
+	Created int64 `json:"created,omitempty"`
+	Updated int64 `json:"updated,omitempty"`
+	// How the reviewer was added, e.g. requested, assigned, self_assigned, default or code_owners
+	Type           string `json:"type,omitempty"`
+	LatestReviewID int    `json:"latest_review_id,omitempty"`
+	ReviewDecision string `json:"review_decision,omitempty"`
+	// Source commit the decision was made on, decisions on older commits may be outdated
+	SHA      string            `json:"sha,omitempty"`
+	Reviewer PullRequestAuthor `json:"reviewer,omitempty"`
+	AddedBy  PullRequestAuthor `json:"added_by,omitempty"`
+}
+
+// UserGroup represents a group of users
+type UserGroup struct {
+	ID          int    `json:"id,omitempty"`
+	Identifier  string `json:"identifier,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// PullRequestUserGroupReviewer represents a user group reviewing a pull request, and the decisions of its users
+type PullRequestUserGroupReviewer struct {
+	Created       int64                         `json:"created,omitempty"`
+	Updated       int64                         `json:"updated,omitempty"`
+	UserGroup     UserGroup                     `json:"user_group,omitempty"`
+	AddedBy       PullRequestAuthor             `json:"added_by,omitempty"`
+	Decision      string                        `json:"decision,omitempty"`
+	SHA           string                        `json:"sha,omitempty"`
+	UserDecisions []PullRequestReviewerDecision `json:"user_decisions,omitempty"`
+}
+
+// PullRequestReviewerDecision represents the review decision of a user of a user group
+type PullRequestReviewerDecision struct {
+	Decision string            `json:"decision,omitempty"`
+	SHA      string            `json:"sha,omitempty"`
+	Reviewer PullRequestAuthor `json:"reviewer,omitempty"`
+}
+
+// AddPullRequestReviewer represents the request body for adding a user as reviewer of a pull request
+type AddPullRequestReviewer struct {
+	ReviewerID int `json:"reviewer_id"`
+}
+
+// AddPullRequestUserGroupReviewer represents the request body for adding a user group as reviewer of a pull request
+type AddPullRequestUserGroupReviewer struct {
+	UserGroupID int `json:"usergroup_id"`
+}
+
+// SubmitPullRequestReview represents the request body for submitting a review decision on a commit of a pull request
+type SubmitPullRequestReview struct {
+	CommitSha string `json:"commit_sha"`
+	Decision  string `json:"decision"`
+}
@@ -38,3 +38,44 @@ type RepositoryOptions struct {
 	Page      int    `json:"page,omitempty"`
 	Limit     int    `json:"limit,omitempty"`
 }
+
+// RepositoryContent represents a file or directory of a repository at a git ref
+type RepositoryContent struct {
+	Type    string                `json:"type,omitempty"`
+	Sha     string                `json:"sha,omitempty"`
+	Name    string                `json:"name,omitempty"`
+	Path    string                `json:"path,omitempty"`
+	Content RepositoryContentBody `json:"content,omitempty"`
+}
+
+// RepositoryContentBody holds the data of a file, or the entries of a directory
+type RepositoryContentBody struct {
+	Encoding string                   `json:"encoding,omitempty"`
+	Data     string                   `json:"data,omitempty"`
+	Size     int64                    `json:"size,omitempty"`
+	DataSize int64                    `json:"data_size,omitempty"`
+	Entries  []RepositoryContentEntry `json:"entries,omitempty"`
+}
+
+// RepositoryContentEntry represents an entry of a directory
+type RepositoryContentEntry struct {
+	Type string `json:"type,omitempty"`
+	Sha  string `json:"sha,omitempty"`
+	Name string `json:"name,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+// FileDiff represents a file changed between two commits, with its unified diff if it was asked for
+type FileDiff struct {
+	SHA         string `json:"sha,omitempty"`
+	OldSHA      string `json:"old_sha,omitempty"`
+	Path        string `json:"path,omitempty"`
+	OldPath     string `json:"old_path,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Additions   int    `json:"additions,omitempty"`
+	Deletions   int    `json:"deletions,omitempty"`
+	Changes     int    `json:"changes,omitempty"`
+	IsBinary    bool   `json:"is_binary,omitempty"`
+	IsSubmodule bool   `json:"is_submodule,omitempty"`
+	Patch       []byte `json:"patch,omitempty"`
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/harness/harness-mcp/client/dto"
+	"github.com/harness/harness-mcp/pkg/textutil"
+)
+
+// headers that may carry the ID Harness uses to correlate a request in its logs
+var requestIDHeaders = []string{"X-Request-Id", "X-Harness-Correlation-Id", "Correlation-Id"}
+
+// APIError is returned when the Harness API responds with an error status code.
+// It wraps the sentinel error for its status code, so callers can use either
+// errors.As to get the details or errors.Is to check for e.g. ErrNotFound.
+type APIError struct {
+	// HTTP status code of the response
+	StatusCode int
+	// Harness error code, e.g. INVALID_REQUEST or ACCESS_DENIED
+	Code string
+	// Human readable error message returned by Harness
+	Message string
+	// Request or correlation ID that can be shared with Harness support
+	RequestID string
+	// HTTP method and path of the failed request
+	Method   string
+	Endpoint string
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s returned status %d", e.Method, e.Endpoint, e.StatusCode)
+	if e.Code != "" {
+		fmt.Fprintf(&b, " (%s)", e.Code)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " [request ID: %s]", e.RequestID)
+	}
+	return b.String()
+}
+
+// Unwrap returns the sentinel error matching the status code.
+func (e *APIError) Unwrap() error {
+	return mapStatusCodeToError(e.StatusCode)
+}
+
+// newAPIError builds an APIError from an error response, reading the Harness error body if there is one.
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+	}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.Endpoint = resp.Request.URL.Path
+	}
+
+	if resp.Body != nil {
+		body, err := io.ReadAll(resp.Body)
+		if err == nil && len(body) > 0 {
+			var errResp dto.ErrorResponse
+			if jsonErr := json.Unmarshal(body, &errResp); jsonErr == nil {
+				apiErr.Code = errResp.Code
+				apiErr.Message = errResp.Message
+				apiErr.RequestID = errResp.CorrelationID
+			} else {
+				// not a Harness error body, keep a bounded amount of it for context
+				apiErr.Message = truncate(strings.TrimSpace(string(body)), 500)
+			}
+		}
+	}
+
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	if apiErr.RequestID == "" {
+		for _, header := range requestIDHeaders {
+			if id := resp.Header.Get(header); id != "" {
+				apiErr.RequestID = id
+				break
+			}
+		}
+	}
+
+	return apiErr
+}
+
+// truncate shortens s to at most n bytes, cutting it at a character boundary.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:textutil.RunePrefixLen(s, n)] + "..."
+}
@@ -0,0 +1,22 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateKeepsCharacters(t *testing.T) {
+	// a 3 byte character straddles the limit
+	s := strings.Repeat("a", 499) + "✓ failed"
+	got := truncate(s, 500)
+	if !utf8.ValidString(got) {
+		t.Fatalf("truncate split a character: %q", got[490:])
+	}
+	if want := strings.Repeat("a", 499) + "..."; got != want {
+		t.Errorf("truncate = %q, want %q", got, want)
+	}
+	if got := truncate("short", 500); got != "short" {
+		t.Errorf("truncate changed a short message to %q", got)
+	}
+}
@@ -1,14 +1,23 @@
 package client
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/harness/harness-mcp/client/dto"
 )
 
 const (
 	logDownloadPath = "log-service/blob/download"
+	logBlobPath     = "log-service/blob"
+	logStreamPath   = "log-service/stream"
+
+	// MaxLogLineSize is the maximum size of a single line of a log
+	MaxLogLineSize = 1024 * 1024
 )
 
 // LogService handles operations related to pipeline logs
@@ -52,11 +61,109 @@ func (l *LogService) DownloadLogs(ctx context.Context, scope dto.Scope, planExec
 	// Initialize the response object
 	response := &dto.LogDownloadResponse{}
 
-	// Make the POST request
-	err = l.client.Post(ctx, logDownloadPath, params, nil, response)
+	// Make the POST request, generating a download link has no side effects so it is safe to retry
+	err = l.client.Post(ctx, logDownloadPath, params, nil, response, l.client.RetryPolicy.NewBackOff())
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch log download URL: %w", err)
 	}
 
 	return response.Link, nil
 }
+
+// StreamLog reads the log stored under logKey, typically the log of a single step, calling fn
+// for every line in order. Reading stops early when fn returns false.
+// Lines that are not in the log service's JSON format are passed on as plain output.
+func (l *LogService) StreamLog(ctx context.Context, scope dto.Scope, logKey string, fn func(dto.LogLine) bool) error {
+	params := make(map[string]string)
+	params["accountID"] = scope.AccountID
+	params["key"] = logKey
+
+	body, err := l.client.GetStream(ctx, logBlobPath, params, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get log: %w", err)
+	}
+	defer body.Close()
+
+	return scanLogLines(body, fn)
+}
+
+// TailLog follows the live log stream of logKey while the step writing it is running, calling fn
+// for every line as it is written. It returns when the stream is closed by the log service,
+// ctx is done or fn returns false.
+func (l *LogService) TailLog(ctx context.Context, scope dto.Scope, logKey string, fn func(dto.LogLine) bool) error {
+	params := make(map[string]string)
+	params["accountID"] = scope.AccountID
+	params["key"] = logKey
+
+	body, err := l.client.GetStream(ctx, logStreamPath, params, map[string]string{"Accept": "text/event-stream"})
+	if err != nil {
+		return fmt.Errorf("failed to open log stream: %w", err)
+	}
+	defer body.Close()
+
+	return scanLogEvents(body, fn)
+}
+
+// scanLogEvents parses the server-sent events of a log stream, each carrying a JSON log line.
+// The log service sends an error event when the stream is closed.
+func scanLogEvents(r io.Reader, fn func(dto.LogLine) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), MaxLogLineSize)
+
+	var event string
+	var data []string
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case text == "":
+			// a blank line dispatches the event
+			if event == "error" {
+				return nil
+			}
+			if len(data) > 0 {
+				if !fn(ParseLogLine([]byte(strings.Join(data, "\n")))) {
+					return nil
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(text, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(text, "event:"))
+		case strings.HasPrefix(text, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(text, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read log stream: %w", err)
+	}
+	return nil
+}
+
+// scanLogLines parses the newline delimited JSON log lines of r.
+func scanLogLines(r io.Reader, fn func(dto.LogLine) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), MaxLogLineSize)
+	for scanner.Scan() {
+		raw := scanner.Bytes()
+		if len(raw) == 0 {
+			continue
+		}
+
+		if !fn(ParseLogLine(raw)) {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read log: %w", err)
+	}
+	return nil
+}
+
+// ParseLogLine parses a line in the log service's JSON format.
+// Lines in any other format are returned as plain output.
+func ParseLogLine(raw []byte) dto.LogLine {
+	var line dto.LogLine
+	if err := json.Unmarshal(raw, &line); err != nil {
+		return dto.LogLine{Out: string(raw)}
+	}
+	return line
+}
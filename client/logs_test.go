@@ -0,0 +1,83 @@
+package client
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/harness/harness-mcp/client/dto"
+)
+
+func TestScanLogEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		// stop makes the callback return false after this many lines, 0 never stops
+		stop    int
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "events",
+			body: "data: {\"out\":\"first\\n\"}\n\ndata: {\"out\":\"second\\n\"}\n\n",
+			want: []string{"first\n", "second\n"},
+		},
+		{
+			name: "data without a space and comments",
+			body: ": keep-alive\n\ndata:{\"out\":\"line\"}\n\n",
+			want: []string{"line"},
+		},
+		{
+			name: "multi-line data",
+			body: "data: {\"out\":\ndata: \"joined\"}\n\n",
+			want: []string{"joined"},
+		},
+		{
+			name: "data that is not JSON",
+			body: "data: plain text\n\n",
+			want: []string{"plain text"},
+		},
+		{
+			name: "error event closes the stream",
+			body: "data: {\"out\":\"before\"}\n\nevent: error\ndata: eof\n\ndata: {\"out\":\"after\"}\n\n",
+			want: []string{"before"},
+		},
+		{
+			name: "event without a terminating blank line",
+			body: "data: {\"out\":\"done\"}\n\ndata: {\"out\":\"partial\"}\n",
+			want: []string{"done"},
+		},
+		{
+			name: "callback stops",
+			body: "data: {\"out\":\"1\"}\n\ndata: {\"out\":\"2\"}\n\ndata: {\"out\":\"3\"}\n\n",
+			stop: 2,
+			want: []string{"1", "2"},
+		},
+		{
+			name:    "line too long",
+			body:    "data: " + strings.Repeat("x", MaxLogLineSize+1) + "\n\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := scanLogEvents(strings.NewReader(tt.body), func(line dto.LogLine) bool {
+				got = append(got, line.Out)
+				return tt.stop == 0 || len(got) < tt.stop
+			})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("scanLogEvents succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("scanLogEvents: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scanLogEvents = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@ package client
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/harness/harness-mcp/client/dto"
 )
 
@@ -13,6 +15,12 @@ const (
 	pipelineExecutionPath        = "pipeline/api/pipelines/execution/url"
 	pipelineExecutionGetPath     = "pipeline/api/pipelines/execution/v2/%s"
 	pipelineExecutionSummaryPath = "pipeline/api/pipelines/execution/summary"
+	pipelineRunPath              = "pipeline/api/pipeline/execute/%s"
+	pipelineRunWithInputSetsPath = "pipeline/api/pipeline/execute/%s/inputSetList"
+	pipelineInterruptPath        = "pipeline/api/pipeline/execute/interrupt/%s"
+	pipelineRetryStagesPath      = "pipeline/api/pipeline/execute/%s/retryStages"
+	pipelineRetryHistoryPath     = "pipeline/api/pipeline/execute/retryHistory/%s"
+	pipelineRetryPath            = "pipeline/api/pipeline/execute/retry/%s"
 )
 
 type PipelineService struct {
@@ -68,8 +76,8 @@ func (p *PipelineService) List(ctx context.Context, scope dto.Scope, opts *dto.P
 	// Initialize the response object
 	response := &dto.ListOutput[dto.PipelineListItem]{}
 
-	// Make the POST request
-	err := p.client.Post(ctx, pipelineListPath, params, requestBody, response)
+	// Make the POST request, listing has no side effects so it is safe to retry
+	err := p.client.Post(ctx, pipelineListPath, params, requestBody, response, p.client.RetryPolicy.NewBackOff())
 	if err != nil {
 		return nil, err
 	}
@@ -120,8 +128,8 @@ func (p *PipelineService) ListExecutions(ctx context.Context, scope dto.Scope, o
 	// Initialize the response object
 	response := &dto.ListOutput[dto.PipelineExecution]{}
 
-	// Make the POST request
-	err := p.client.Post(ctx, pipelineExecutionSummaryPath, params, requestBody, response)
+	// Make the POST request, listing has no side effects so it is safe to retry
+	err := p.client.Post(ctx, pipelineExecutionSummaryPath, params, requestBody, response, p.client.RetryPolicy.NewBackOff())
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pipeline executions: %w", err)
 	}
@@ -155,6 +163,27 @@ func (p *PipelineService) GetExecution(ctx context.Context, scope dto.Scope, pla
 	return result, nil
 }
 
+// GetExecutionGraph retrieves a pipeline execution along with the full graph of its stages and steps
+func (p *PipelineService) GetExecutionGraph(ctx context.Context, scope dto.Scope, planExecutionID string) (*dto.Entity[dto.PipelineExecutionGraphResponse], error) {
+	path := fmt.Sprintf(pipelineExecutionGetPath, planExecutionID)
+
+	// Prepare query parameters
+	params := make(map[string]string)
+	addScope(scope, params)
+	params["renderFullBottomGraph"] = "true"
+
+	// Initialize the response object
+	response := &dto.Entity[dto.PipelineExecutionGraphResponse]{}
+
+	// Make the GET request
+	err := p.client.Get(ctx, path, params, map[string]string{}, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get execution graph: %w", err)
+	}
+
+	return response, nil
+}
+
 func (p *PipelineService) FetchExecutionURL(ctx context.Context, scope dto.Scope, pipelineID, planExecutionID string) (string, error) {
 	path := pipelineExecutionPath
 
@@ -167,11 +196,153 @@ func (p *PipelineService) FetchExecutionURL(ctx context.Context, scope dto.Scope
 	// Initialize the response object
 	urlResponse := &dto.Entity[string]{}
 
-	// Make the POST request
-	err := p.client.Post(ctx, path, params, nil, urlResponse)
+	// Make the POST request, fetching the URL has no side effects so it is safe to retry
+	err := p.client.Post(ctx, path, params, nil, urlResponse, p.client.RetryPolicy.NewBackOff())
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch execution URL: %w", err)
 	}
 
 	return urlResponse.Data, nil
 }
+
+// Run executes a pipeline with the given runtime inputs and returns the started plan execution
+func (p *PipelineService) Run(ctx context.Context, scope dto.Scope, pipelineID string, opts *dto.PipelineRunOptions) (*dto.Entity[dto.PipelineRunResponse], error) {
+	// Handle nil options by creating default options
+	if opts == nil {
+		opts = &dto.PipelineRunOptions{}
+	}
+
+	// Prepare query parameters
+	params := make(map[string]string)
+	addScope(scope, params)
+	if opts.Branch != "" {
+		params["branch"] = opts.Branch
+	}
+	if opts.RepoIdentifier != "" {
+		params["repoIdentifier"] = opts.RepoIdentifier
+	}
+
+	// Initialize the response object
+	response := &dto.Entity[dto.PipelineRunResponse]{}
+
+	// Runs are not idempotent, so these requests are never retried
+	if len(opts.InputSetReferences) > 0 {
+		path := fmt.Sprintf(pipelineRunWithInputSetsPath, pipelineID)
+		requestBody := &dto.PipelineInputSetRunRequest{
+			InputSetReferences: opts.InputSetReferences,
+			LastYamlToMerge:    opts.RuntimeInputYAML,
+		}
+
+		err := p.client.Post(ctx, path, params, requestBody, response)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run pipeline: %w", err)
+		}
+		return response, nil
+	}
+
+	// Without input sets the runtime input YAML is sent as the raw request body
+	path := fmt.Sprintf(pipelineRunPath, pipelineID)
+	headers := map[string]string{"Content-Type": "application/yaml"}
+
+	err := p.client.PostRaw(ctx, path, params, strings.NewReader(opts.RuntimeInputYAML), headers, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run pipeline: %w", err)
+	}
+
+	return response, nil
+}
+
+// Interrupt aborts, pauses or resumes a pipeline execution, depending on the interrupt type
+func (p *PipelineService) Interrupt(ctx context.Context, scope dto.Scope, planExecutionID string, interruptType string) (*dto.Entity[dto.InterruptResponse], error) {
+	path := fmt.Sprintf(pipelineInterruptPath, planExecutionID)
+
+	// Prepare query parameters
+	params := make(map[string]string)
+	addScope(scope, params)
+	params["interruptType"] = interruptType
+
+	// Initialize the response object
+	response := &dto.Entity[dto.InterruptResponse]{}
+
+	// Make the PUT request without a body, an interrupt that timed out may have been applied so it is never retried
+	err := p.client.PutRaw(ctx, path, params, nil, nil, response, &backoff.StopBackOff{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to interrupt execution: %w", err)
+	}
+
+	return response, nil
+}
+
+// GetRetryStages retrieves the stages a pipeline execution can be retried from
+func (p *PipelineService) GetRetryStages(ctx context.Context, scope dto.Scope, pipelineID, planExecutionID string) (*dto.Entity[dto.RetryInfo], error) {
+	path := fmt.Sprintf(pipelineRetryStagesPath, planExecutionID)
+
+	// Prepare query parameters
+	params := make(map[string]string)
+	addScope(scope, params)
+	params["pipelineIdentifier"] = pipelineID
+
+	// Initialize the response object
+	response := &dto.Entity[dto.RetryInfo]{}
+
+	// Make the GET request
+	err := p.client.Get(ctx, path, params, map[string]string{}, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retry stages: %w", err)
+	}
+
+	return response, nil
+}
+
+// GetRetryHistory retrieves the previous retries of a pipeline execution
+func (p *PipelineService) GetRetryHistory(ctx context.Context, scope dto.Scope, pipelineID, planExecutionID string) (*dto.Entity[dto.RetryHistory], error) {
+	path := fmt.Sprintf(pipelineRetryHistoryPath, planExecutionID)
+
+	// Prepare query parameters
+	params := make(map[string]string)
+	addScope(scope, params)
+	params["pipelineIdentifier"] = pipelineID
+
+	// Initialize the response object
+	response := &dto.Entity[dto.RetryHistory]{}
+
+	// Make the GET request
+	err := p.client.Get(ctx, path, params, map[string]string{}, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retry history: %w", err)
+	}
+
+	return response, nil
+}
+
+// Retry retries a pipeline execution from the given stages and returns the new plan execution
+func (p *PipelineService) Retry(ctx context.Context, scope dto.Scope, pipelineID, planExecutionID string, opts *dto.PipelineRetryOptions) (*dto.Entity[dto.PipelineRunResponse], error) {
+	path := fmt.Sprintf(pipelineRetryPath, pipelineID)
+
+	// Handle nil options by creating default options
+	if opts == nil {
+		opts = &dto.PipelineRetryOptions{}
+	}
+
+	// Prepare query parameters, retryStages is repeated once per stage
+	params := make(map[string]string)
+	addScope(scope, params)
+	params["planExecutionId"] = planExecutionID
+	params["runAllStages"] = fmt.Sprintf("%t", opts.RunAllStages)
+	query := queryValues(params)
+	for _, stage := range opts.RetryStages {
+		query.Add("retryStages", stage)
+	}
+
+	// Initialize the response object
+	response := &dto.Entity[dto.PipelineRunResponse]{}
+
+	// Every retry starts a new execution, so the request itself must never be retried
+	headers := map[string]string{"Content-Type": "application/yaml"}
+	err := p.client.PutRawValues(ctx, path, query, strings.NewReader(opts.RuntimeInputYAML), headers, response, &backoff.StopBackOff{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retry execution: %w", err)
+	}
+
+	return response, nil
+}
@@ -14,6 +14,19 @@ const (
 	pullRequestListPath   = pullRequestBasePath + "/%s/pullreq"
 	pullRequestCreatePath = pullRequestBasePath + "/%s/pullreq"
 	pullRequestChecksPath = pullRequestBasePath + "/%s/pullreq/%d/checks"
+	pullRequestMergePath  = pullRequestBasePath + "/%s/pullreq/%d/merge"
+	pullRequestUpdatePath = pullRequestBasePath + "/%s/pullreq/%d"
+	pullRequestStatePath  = pullRequestBasePath + "/%s/pullreq/%d/state"
+
+	pullRequestActivitiesPath    = pullRequestBasePath + "/%s/pullreq/%d/activities"
+	pullRequestCommentsPath      = pullRequestBasePath + "/%s/pullreq/%d/comments"
+	pullRequestCommentStatusPath = pullRequestBasePath + "/%s/pullreq/%d/comments/%d/status"
+
+	pullRequestReviewersPath          = pullRequestBasePath + "/%s/pullreq/%d/reviewers"
+	pullRequestReviewerPath           = pullRequestBasePath + "/%s/pullreq/%d/reviewers/%d"
+	pullRequestUserGroupReviewersPath = pullRequestBasePath + "/%s/pullreq/%d/reviewers/usergroups"
+	pullRequestUserGroupReviewerPath  = pullRequestBasePath + "/%s/pullreq/%d/reviewers/usergroups/%d"
+	pullRequestReviewsPath            = pullRequestBasePath + "/%s/pullreq/%d/reviews"
 )
 
 type PullRequestService struct {
@@ -142,6 +155,36 @@ func (p *PullRequestService) Create(ctx context.Context, scope dto.Scope, repoID
 	return pr, nil
 }
 
+// Update updates the title, description or target branch of a pull request. Fields that are not set are left unchanged.
+func (p *PullRequestService) Update(ctx context.Context, scope dto.Scope, repoID string, prNumber int, update *dto.UpdatePullRequest) (*dto.PullRequest, error) {
+	path := fmt.Sprintf(pullRequestUpdatePath, repoID, prNumber)
+	params := make(map[string]string)
+	addScope(scope, params)
+
+	pr := new(dto.PullRequest)
+	err := p.client.Patch(ctx, path, params, update, pr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pull request: %w", err)
+	}
+
+	return pr, nil
+}
+
+// UpdateState closes or reopens a pull request, or marks it as draft or ready for review
+func (p *PullRequestService) UpdateState(ctx context.Context, scope dto.Scope, repoID string, prNumber int, state *dto.PullRequestState) (*dto.PullRequest, error) {
+	path := fmt.Sprintf(pullRequestStatePath, repoID, prNumber)
+	params := make(map[string]string)
+	addScope(scope, params)
+
+	pr := new(dto.PullRequest)
+	err := p.client.Post(ctx, path, params, state, pr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pull request state: %w", err)
+	}
+
+	return pr, nil
+}
+
 // GetChecks retrieves the status checks for a specific pull request
 func (p *PullRequestService) GetChecks(ctx context.Context, scope dto.Scope, repoID string, prNumber int) (*dto.PullRequestChecksResponse, error) {
 	path := fmt.Sprintf(pullRequestChecksPath, repoID, prNumber)
@@ -156,3 +199,181 @@ func (p *PullRequestService) GetChecks(ctx context.Context, scope dto.Scope, rep
 
 	return checks, nil
 }
+
+// Merge merges a pull request, or only checks whether it can be merged if merge.DryRun is set
+func (p *PullRequestService) Merge(ctx context.Context, scope dto.Scope, repoID string, prNumber int, merge *dto.MergePullRequest) (*dto.MergeResponse, error) {
+	path := fmt.Sprintf(pullRequestMergePath, repoID, prNumber)
+	params := make(map[string]string)
+	addScope(scope, params)
+
+	response := new(dto.MergeResponse)
+	err := p.client.Post(ctx, path, params, merge, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge pull request: %w", err)
+	}
+
+	return response, nil
+}
+
+// ListActivities lists the activities of a pull request: comments, comments on code and changes of the pull request
+func (p *PullRequestService) ListActivities(ctx context.Context, scope dto.Scope, repoID string, prNumber int, opts *dto.PullRequestActivityOptions) ([]*dto.PullRequestActivity, error) {
+	path := fmt.Sprintf(pullRequestActivitiesPath, repoID, prNumber)
+	params := make(map[string]string)
+	addScope(scope, params)
+
+	if opts == nil {
+		opts = &dto.PullRequestActivityOptions{}
+	}
+	if opts.Kind != "" {
+		params["kind"] = opts.Kind
+	}
+	if opts.After > 0 {
+		params["after"] = fmt.Sprintf("%d", opts.After)
+	}
+	if opts.Before > 0 {
+		params["before"] = fmt.Sprintf("%d", opts.Before)
+	}
+	if opts.Limit > 0 {
+		params["limit"] = fmt.Sprintf("%d", opts.Limit)
+	}
+
+	var activities []*dto.PullRequestActivity
+	err := p.client.Get(ctx, path, params, nil, &activities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull request activities: %w", err)
+	}
+
+	return activities, nil
+}
+
+// CreateComment comments on a pull request, on lines of a file of the pull request, or replies to a comment
+func (p *PullRequestService) CreateComment(ctx context.Context, scope dto.Scope, repoID string, prNumber int, comment *dto.CreatePullRequestComment) (*dto.PullRequestActivity, error) {
+	path := fmt.Sprintf(pullRequestCommentsPath, repoID, prNumber)
+	params := make(map[string]string)
+	addScope(scope, params)
+
+	activity := new(dto.PullRequestActivity)
+	err := p.client.Post(ctx, path, params, comment, activity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pull request comment: %w", err)
+	}
+
+	return activity, nil
+}
+
+// UpdateCommentStatus resolves a comment of a pull request, along with its replies, or reopens it
+func (p *PullRequestService) UpdateCommentStatus(ctx context.Context, scope dto.Scope, repoID string, prNumber int, commentID int, status string) (*dto.PullRequestActivity, error) {
+	path := fmt.Sprintf(pullRequestCommentStatusPath, repoID, prNumber, commentID)
+	params := make(map[string]string)
+	addScope(scope, params)
+
+	activity := new(dto.PullRequestActivity)
+	err := p.client.Put(ctx, path, params, &dto.PullRequestCommentStatus{Status: status}, activity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pull request comment status: %w", err)
+	}
+
+	return activity, nil
+}
+
+// ListReviewers lists the users reviewing a pull request, with their latest review decisions
+func (p *PullRequestService) ListReviewers(ctx context.Context, scope dto.Scope, repoID string, prNumber int) ([]*dto.PullRequestReviewer, error) {
+	path := fmt.Sprintf(pullRequestReviewersPath, repoID, prNumber)
+	params := make(map[string]string)
+	addScope(scope, params)
+
+	var reviewers []*dto.PullRequestReviewer
+	err := p.client.Get(ctx, path, params, nil, &reviewers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull request reviewers: %w", err)
+	}
+
+	return reviewers, nil
+}
+
+// AddReviewer adds a user as reviewer of a pull request
+func (p *PullRequestService) AddReviewer(ctx context.Context, scope dto.Scope, repoID string, prNumber int, reviewerID int) (*dto.PullRequestReviewer, error) {
+	path := fmt.Sprintf(pullRequestReviewersPath, repoID, prNumber)
+	params := make(map[string]string)
+	addScope(scope, params)
+
+	reviewer := new(dto.PullRequestReviewer)
+	err := p.client.Put(ctx, path, params, &dto.AddPullRequestReviewer{ReviewerID: reviewerID}, reviewer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add pull request reviewer: %w", err)
+	}
+
+	return reviewer, nil
+}
+
+// RemoveReviewer removes a user from the reviewers of a pull request
+func (p *PullRequestService) RemoveReviewer(ctx context.Context, scope dto.Scope, repoID string, prNumber int, reviewerID int) error {
+	path := fmt.Sprintf(pullRequestReviewerPath, repoID, prNumber, reviewerID)
+	params := make(map[string]string)
+	addScope(scope, params)
+
+	err := p.client.Delete(ctx, path, params, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove pull request reviewer: %w", err)
+	}
+
+	return nil
+}
+
+// ListUserGroupReviewers lists the user groups reviewing a pull request, with the decisions of their users
+func (p *PullRequestService) ListUserGroupReviewers(ctx context.Context, scope dto.Scope, repoID string, prNumber int) ([]*dto.PullRequestUserGroupReviewer, error) {
+	path := fmt.Sprintf(pullRequestUserGroupReviewersPath, repoID, prNumber)
+	params := make(map[string]string)
+	addScope(scope, params)
+
+	var reviewers []*dto.PullRequestUserGroupReviewer
+	err := p.client.Get(ctx, path, params, nil, &reviewers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull request user group reviewers: %w", err)
+	}
+
+	return reviewers, nil
+}
+
+// AddUserGroupReviewer adds a user group as reviewer of a pull request
+func (p *PullRequestService) AddUserGroupReviewer(ctx context.Context, scope dto.Scope, repoID string, prNumber int, userGroupID int) (*dto.PullRequestUserGroupReviewer, error) {
+	path := fmt.Sprintf(pullRequestUserGroupReviewersPath, repoID, prNumber)
+	params := make(map[string]string)
+	addScope(scope, params)
+
+	reviewer := new(dto.PullRequestUserGroupReviewer)
+	err := p.client.Put(ctx, path, params, &dto.AddPullRequestUserGroupReviewer{UserGroupID: userGroupID}, reviewer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add pull request user group reviewer: %w", err)
+	}
+
+	return reviewer, nil
+}
+
+// RemoveUserGroupReviewer removes a user group from the reviewers of a pull request
+func (p *PullRequestService) RemoveUserGroupReviewer(ctx context.Context, scope dto.Scope, repoID string, prNumber int, userGroupID int) error {
+	path := fmt.Sprintf(pullRequestUserGroupReviewerPath, repoID, prNumber, userGroupID)
+	params := make(map[string]string)
+	addScope(scope, params)
+
+	err := p.client.Delete(ctx, path, params, nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove pull request user group reviewer: %w", err)
+	}
+
+	return nil
+}
+
+// SubmitReview submits a review decision on a source commit of a pull request
+func (p *PullRequestService) SubmitReview(ctx context.Context, scope dto.Scope, repoID string, prNumber int, review *dto.SubmitPullRequestReview) error {
+	path := fmt.Sprintf(pullRequestReviewsPath, repoID, prNumber)
+	params := make(map[string]string)
+	addScope(scope, params)
+
+	err := p.client.Post(ctx, path, params, review, nil)
+	if err != nil {
+		return fmt.Errorf("failed to submit pull request review: %w", err)
+	}
+
+	return nil
+}
@@ -3,14 +3,17 @@ package client
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/harness/harness-mcp/client/dto"
 )
 
 const (
-	repositoryBasePath = "code/api/v1/repos"
-	repositoryGetPath  = repositoryBasePath + "/%s"
-	repositoryListPath = repositoryBasePath
+	repositoryBasePath    = "code/api/v1/repos"
+	repositoryGetPath     = repositoryBasePath + "/%s"
+	repositoryListPath    = repositoryBasePath
+	repositoryContentPath = repositoryBasePath + "/%s/content/%s"
+	repositoryDiffPath    = repositoryBasePath + "/%s/diff/%s...%s"
 )
 
 type RepositoryService struct {
@@ -80,3 +83,51 @@ func (r *RepositoryService) List(ctx context.Context, scope dto.Scope, opts *dto
 
 	return repos, nil
 }
+
+// GetContent gets the content of a file, or the entries of a directory, of a repository at a git ref.
+// If ref is empty, the default branch is used.
+func (r *RepositoryService) GetContent(ctx context.Context, scope dto.Scope, repoIdentifier, ref, filePath string) (*dto.RepositoryContent, error) {
+	path := fmt.Sprintf(repositoryContentPath, repoIdentifier, escapePath(filePath))
+	params := make(map[string]string)
+	addScope(scope, params)
+	params["include_commit"] = "false"
+	if ref != "" {
+		params["git_ref"] = ref
+	}
+
+	content := new(dto.RepositoryContent)
+	err := r.client.Get(ctx, path, params, nil, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository content: %w", err)
+	}
+
+	return content, nil
+}
+
+// GetDiff gets the files changed between the merge base of two commits and the head commit, with their
+// unified diffs if includePatch is set. If paths are given, only the files at these paths are returned.
+func (r *RepositoryService) GetDiff(ctx context.Context, scope dto.Scope, repoIdentifier, base, head string, includePatch bool, paths []string) ([]*dto.FileDiff, error) {
+	path := fmt.Sprintf(repositoryDiffPath, repoIdentifier, base, head)
+	params := make(map[string]string)
+	addScope(scope, params)
+	if includePatch {
+		params["include_patch"] = "true"
+	}
+
+	// paths may contain commas, so they are added as values of their own
+	query := url.Values{}
+	for key, value := range params {
+		query.Set(key, value)
+	}
+	for _, p := range paths {
+		query.Add("path", p)
+	}
+
+	var files []*dto.FileDiff
+	err := r.client.GetValues(ctx, path, query, map[string]string{"Accept": "application/json"}, &files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff: %w", err)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// RetryPolicy configures how failed requests are retried.
+// Requests are retried on transport errors, 429 and 5xx responses.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries for a single call, 0 disables retries
+	MaxRetries int
+	// InitialInterval is the wait before the first retry, it grows exponentially with jitter
+	InitialInterval time.Duration
+	// MaxInterval caps the wait between two retries
+	MaxInterval time.Duration
+	// MaxElapsedTime is the total time budget for a call including all its retries, 0 means no limit
+	MaxElapsedTime time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:      3,
+		InitialInterval: 500 * time.Millisecond,
+		MaxInterval:     10 * time.Second,
+		MaxElapsedTime:  30 * time.Second,
+	}
+}
+
+// NewBackOff returns a jittered exponential backoff following the policy.
+// It can be passed to Post, Put, Patch and Delete to opt in to retries for requests that are safe to repeat.
+func (p RetryPolicy) NewBackOff() backoff.BackOff {
+	if p.MaxRetries <= 0 {
+		return &backoff.StopBackOff{}
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = p.InitialInterval
+	b.MaxInterval = p.MaxInterval
+	b.MaxElapsedTime = p.MaxElapsedTime
+	b.RandomizationFactor = 0.5
+	b.Reset()
+
+	return backoff.WithMaxRetries(b, uint64(p.MaxRetries))
+}
+
+// isIdempotent reports whether requests with the given method can be retried without side effects.
+// Writes are never retried automatically: a request that timed out may have been applied already,
+// so each call that is safe to repeat opts in by passing a backoff.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter parses the Retry-After header, which is either a number of seconds or an HTTP date.
+// It returns 0 if the header is missing or invalid.
+func parseRetryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
+
+// retryableError marks a failed attempt that can be retried.
+type retryableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":     0,
+		"120":  2 * time.Minute,
+		"0":    0,
+		"-5":   0,
+		"1.5":  0,
+		"soon": 0,
+	}
+	for value, want := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if value != "" {
+			resp.Header.Set("Retry-After", value)
+		}
+		if got := parseRetryAfter(resp); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestParseRetryAfterDate(t *testing.T) {
+	// dates have a precision of a second and the clock keeps running, so only rough bounds are checked
+	future := &http.Response{Header: http.Header{}}
+	future.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if got := parseRetryAfter(future); got <= 0 || got > time.Minute {
+		t.Errorf("parseRetryAfter(a minute from now) = %v, want up to a minute", got)
+	}
+
+	past := &http.Response{Header: http.Header{}}
+	past.Header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	if got := parseRetryAfter(past); got != 0 {
+		t.Errorf("parseRetryAfter(a minute ago) = %v, want 0", got)
+	}
+}
+
+func TestIsIdempotent(t *testing.T) {
+	tests := map[string]bool{
+		http.MethodGet:     true,
+		http.MethodHead:    true,
+		http.MethodOptions: true,
+		http.MethodPost:    false,
+		http.MethodPut:     false,
+		http.MethodPatch:   false,
+		http.MethodDelete:  false,
+	}
+	for method, want := range tests {
+		if got := isIdempotent(method); got != want {
+			t.Errorf("isIdempotent(%s) = %v, want %v", method, got, want)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type Config struct {
 	Version     string
 	BaseURL     string
@@ -11,4 +13,33 @@ type Config struct {
 	Toolsets    []string
 	LogFilePath string
 	Debug       bool
+
+	// DynamicToolsets only registers the tools listing and enabling toolsets, which are enabled at runtime
+	DynamicToolsets bool
+
+	// Authentication settings, AuthType selects which of these is used
+	AuthType         string
+	BearerToken      string
+	TokenFile        string
+	TokenCommand     string
+	TokenCommandArgs []string
+	TokenCommandTTL  time.Duration
+	TokenType        string
+
+	// Retry settings for requests to Harness
+	MaxRetries           int
+	RetryInitialInterval time.Duration
+	RetryMaxInterval     time.Duration
+	RetryBudget          time.Duration
+
+	// Redaction of secrets in tool results
+	Redact               bool
+	RedactionPatterns    []string
+	RedactionHighEntropy bool
+
+	// HTTP transport settings, only used by the http command
+	HTTPAddr        string
+	TLSCertFile     string
+	TLSKeyFile      string
+	ShutdownTimeout time.Duration
 }
@@ -2,23 +2,35 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/harness/harness-mcp/client"
 	"github.com/harness/harness-mcp/cmd/harness-mcp-server/config"
 	"github.com/harness/harness-mcp/pkg/harness"
+	"github.com/harness/harness-mcp/pkg/redact"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+const (
+	authTypeAPIKey    = "api-key"
+	authTypeBearer    = "bearer"
+	authTypeTokenFile = "token-file"
+	authTypeExec      = "exec"
+)
+
 var version = "0.1.0"
 var commit = "dev"
 var date = "unknown"
@@ -36,38 +48,154 @@ var (
 		Short: "Start stdio server",
 		Long:  `Start a server that communicates via standard input/output streams using JSON-RPC messages.`,
 		RunE: func(_ *cobra.Command, _ []string) error {
-			token := viper.GetString("api_key")
-			if token == "" {
-				return fmt.Errorf("API key not provided")
+			cfg, err := loadConfig(true)
+			if err != nil {
+				return err
 			}
 
-			var toolsets []string
-			err := viper.UnmarshalKey("toolsets", &toolsets)
-			if err != nil {
-				return fmt.Errorf("Failed to unmarshal toolsets: %w", err)
+			if err := runStdioServer(cfg); err != nil {
+				return fmt.Errorf("failed to run stdio server: %w", err)
 			}
+			return nil
+		},
+	}
 
-			cfg := config.Config{
-				Version:     version,
-				BaseURL:     viper.GetString("base_url"),
-				AccountID:   viper.GetString("account_id"),
-				OrgID:       viper.GetString("org_id"),
-				ProjectID:   viper.GetString("project_id"),
-				APIKey:      viper.GetString("api_key"),
-				ReadOnly:    viper.GetBool("read_only"),
-				Toolsets:    toolsets,
-				LogFilePath: viper.GetString("log_file"),
-				Debug:       viper.GetBool("debug"),
+	httpCmd = &cobra.Command{
+		Use:   "http",
+		Short: "Start HTTP server",
+		Long:  `Start a server that communicates over HTTP, exposing both the SSE and the streamable-HTTP MCP transports.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			// Credentials are optional here since callers can send their own
+			cfg, err := loadConfig(false)
+			if err != nil {
+				return err
+			}
+			// Enabling a toolset registers its tools with the server, which changes the tools of every session
+			if cfg.DynamicToolsets {
+				return fmt.Errorf("dynamic toolsets are only supported with the stdio transport")
 			}
 
-			if err := runStdioServer(cfg); err != nil {
-				return fmt.Errorf("failed to run stdio server: %w", err)
+			if err := runHTTPServer(cfg); err != nil {
+				return fmt.Errorf("failed to run http server: %w", err)
 			}
 			return nil
 		},
 	}
 )
 
+// loadConfig builds the server configuration from flags and environment variables.
+// If requireCredentials is set, the selected auth type must be fully configured.
+func loadConfig(requireCredentials bool) (config.Config, error) {
+	var toolsets []string
+	err := viper.UnmarshalKey("toolsets", &toolsets)
+	if err != nil {
+		return config.Config{}, fmt.Errorf("Failed to unmarshal toolsets: %w", err)
+	}
+
+	cfg := config.Config{
+		Version:              version,
+		BaseURL:              viper.GetString("base_url"),
+		AccountID:            viper.GetString("account_id"),
+		OrgID:                viper.GetString("org_id"),
+		ProjectID:            viper.GetString("project_id"),
+		APIKey:               viper.GetString("api_key"),
+		ReadOnly:             viper.GetBool("read_only"),
+		Toolsets:             toolsets,
+		DynamicToolsets:      viper.GetBool("dynamic_toolsets"),
+		LogFilePath:          viper.GetString("log_file"),
+		Debug:                viper.GetBool("debug"),
+		AuthType:             viper.GetString("auth_type"),
+		BearerToken:          viper.GetString("bearer_token"),
+		TokenFile:            viper.GetString("token_file"),
+		TokenCommand:         viper.GetString("token_command"),
+		TokenCommandArgs:     viper.GetStringSlice("token_command_args"),
+		TokenCommandTTL:      viper.GetDuration("token_command_ttl"),
+		TokenType:            viper.GetString("token_type"),
+		MaxRetries:           viper.GetInt("max_retries"),
+		RetryInitialInterval: viper.GetDuration("retry_initial_interval"),
+		RetryMaxInterval:     viper.GetDuration("retry_max_interval"),
+		RetryBudget:          viper.GetDuration("retry_budget"),
+		Redact:               viper.GetBool("redact"),
+		RedactionPatterns:    stringListValue("redaction_patterns"),
+		RedactionHighEntropy: viper.GetBool("redaction_high_entropy"),
+		HTTPAddr:             viper.GetString("http_addr"),
+		TLSCertFile:          viper.GetString("tls_cert_file"),
+		TLSKeyFile:           viper.GetString("tls_key_file"),
+		ShutdownTimeout:      viper.GetDuration("shutdown_timeout"),
+	}
+
+	if err := validateAuthConfig(cfg, requireCredentials); err != nil {
+		return config.Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// stringListValue reads a list setting whose values may contain commas and spaces, like regular expressions.
+// The flag is repeated to pass several values, the environment variable holds one value per line.
+func stringListValue(key string) []string {
+	value, ok := viper.Get(key).(string)
+	if !ok {
+		return viper.GetStringSlice(key)
+	}
+
+	var values []string
+	for _, line := range strings.Split(value, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			values = append(values, line)
+		}
+	}
+	return values
+}
+
+// validateAuthConfig checks that the settings needed by the selected auth type are present.
+func validateAuthConfig(cfg config.Config, requireCredentials bool) error {
+	switch cfg.AuthType {
+	case authTypeAPIKey:
+		if cfg.APIKey == "" && requireCredentials {
+			return fmt.Errorf("API key not provided")
+		}
+	case authTypeBearer:
+		if cfg.BearerToken == "" && requireCredentials {
+			return fmt.Errorf("bearer token not provided")
+		}
+	case authTypeTokenFile:
+		if cfg.TokenFile == "" {
+			return fmt.Errorf("token file not provided")
+		}
+	case authTypeExec:
+		if cfg.TokenCommand == "" {
+			return fmt.Errorf("token command not provided")
+		}
+	default:
+		return fmt.Errorf("unknown auth type %q, must be one of: %s, %s, %s, %s",
+			cfg.AuthType, authTypeAPIKey, authTypeBearer, authTypeTokenFile, authTypeExec)
+	}
+
+	// an empty token type sends the token as an API key, like TokenTypeAPIKey
+	switch client.TokenType(cfg.TokenType) {
+	case "", client.TokenTypeAPIKey, client.TokenTypeBearer:
+	default:
+		return fmt.Errorf("unknown token type %q, must be one of: %s, %s", cfg.TokenType, client.TokenTypeAPIKey, client.TokenTypeBearer)
+	}
+
+	return nil
+}
+
+// newAuthProvider creates the auth provider for the configured auth type.
+func newAuthProvider(cfg config.Config) client.AuthProvider {
+	switch cfg.AuthType {
+	case authTypeBearer:
+		return client.NewBearerTokenProvider(cfg.BearerToken)
+	case authTypeTokenFile:
+		return client.NewFileTokenProvider(cfg.TokenFile, client.TokenType(cfg.TokenType))
+	case authTypeExec:
+		return client.NewExecTokenProvider(cfg.TokenCommand, cfg.TokenCommandArgs, client.TokenType(cfg.TokenType), cfg.TokenCommandTTL)
+	default:
+		return client.NewAPIKeyProvider(cfg.APIKey)
+	}
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
@@ -75,28 +203,70 @@ func init() {
 
 	// Add global flags
 	rootCmd.PersistentFlags().StringSlice("toolsets", harness.DefaultTools, "An optional comma separated list of groups of tools to allow, defaults to enabling all")
+	rootCmd.PersistentFlags().Bool("dynamic-toolsets", false, "Only register the tools to list and enable toolsets, and enable toolsets at runtime when they are needed")
 	rootCmd.PersistentFlags().Bool("read-only", false, "Restrict the server to read-only operations")
 	rootCmd.PersistentFlags().String("log-file", "", "Path to log file")
 	rootCmd.PersistentFlags().Bool("debug", false, "Enable debug logging")
 	rootCmd.PersistentFlags().String("base-url", "https://app.harness.io", "Base URL for Harness")
 	rootCmd.PersistentFlags().String("api-key", "", "API key for authentication")
+	rootCmd.PersistentFlags().String("auth-type", authTypeAPIKey, "Authentication method to use: api-key, bearer, token-file or exec")
+	rootCmd.PersistentFlags().String("bearer-token", "", "Bearer token (JWT) for authentication, used with --auth-type=bearer")
+	rootCmd.PersistentFlags().String("token-file", "", "Path to a file containing the token, re-read when it changes, used with --auth-type=token-file")
+	rootCmd.PersistentFlags().String("token-command", "", "Credential helper command printing the token to stdout, used with --auth-type=exec")
+	rootCmd.PersistentFlags().StringSlice("token-command-args", nil, "Arguments for the credential helper command")
+	rootCmd.PersistentFlags().Duration("token-command-ttl", 5*time.Minute, "How long a token returned by the credential helper is cached")
+	rootCmd.PersistentFlags().String("token-type", string(client.TokenTypeAPIKey), "How a token from --token-file or --token-command is sent: api-key or bearer")
+	rootCmd.PersistentFlags().Int("max-retries", client.DefaultRetryPolicy().MaxRetries, "Maximum number of retries for a failed request to Harness, 0 disables retries")
+	rootCmd.PersistentFlags().Duration("retry-initial-interval", client.DefaultRetryPolicy().InitialInterval, "Wait before the first retry, grows exponentially with jitter")
+	rootCmd.PersistentFlags().Duration("retry-max-interval", client.DefaultRetryPolicy().MaxInterval, "Maximum wait between two retries")
+	rootCmd.PersistentFlags().Duration("retry-budget", client.DefaultRetryPolicy().MaxElapsedTime, "Total time budget for a request including its retries, 0 means no limit")
+	rootCmd.PersistentFlags().Bool("redact", true, "Redact secrets like tokens, passwords and keys from tool results")
+	rootCmd.PersistentFlags().StringArray("redaction-patterns", nil, "Additional regular expression of secrets to redact, can be repeated. Only the first capture group is redacted if the pattern has one")
+	rootCmd.PersistentFlags().Bool("redaction-high-entropy", true, "Also redact long random looking strings, which are likely keys or tokens")
 	rootCmd.PersistentFlags().String("account-id", "", "Account ID to use")
 	rootCmd.PersistentFlags().String("org-id", "", "(Optional) org ID to use")
 	rootCmd.PersistentFlags().String("project-id", "", "(Optional) project ID to use")
 
 	// Bind flags to viper
 	_ = viper.BindPFlag("toolsets", rootCmd.PersistentFlags().Lookup("toolsets"))
+	_ = viper.BindPFlag("dynamic_toolsets", rootCmd.PersistentFlags().Lookup("dynamic-toolsets"))
 	_ = viper.BindPFlag("read_only", rootCmd.PersistentFlags().Lookup("read-only"))
 	_ = viper.BindPFlag("log_file", rootCmd.PersistentFlags().Lookup("log-file"))
 	_ = viper.BindPFlag("debug", rootCmd.PersistentFlags().Lookup("debug"))
 	_ = viper.BindPFlag("base_url", rootCmd.PersistentFlags().Lookup("base-url"))
 	_ = viper.BindPFlag("api_key", rootCmd.PersistentFlags().Lookup("api-key"))
+	_ = viper.BindPFlag("auth_type", rootCmd.PersistentFlags().Lookup("auth-type"))
+	_ = viper.BindPFlag("bearer_token", rootCmd.PersistentFlags().Lookup("bearer-token"))
+	_ = viper.BindPFlag("token_file", rootCmd.PersistentFlags().Lookup("token-file"))
+	_ = viper.BindPFlag("token_command", rootCmd.PersistentFlags().Lookup("token-command"))
+	_ = viper.BindPFlag("token_command_args", rootCmd.PersistentFlags().Lookup("token-command-args"))
+	_ = viper.BindPFlag("token_command_ttl", rootCmd.PersistentFlags().Lookup("token-command-ttl"))
+	_ = viper.BindPFlag("token_type", rootCmd.PersistentFlags().Lookup("token-type"))
+	_ = viper.BindPFlag("max_retries", rootCmd.PersistentFlags().Lookup("max-retries"))
+	_ = viper.BindPFlag("retry_initial_interval", rootCmd.PersistentFlags().Lookup("retry-initial-interval"))
+	_ = viper.BindPFlag("retry_max_interval", rootCmd.PersistentFlags().Lookup("retry-max-interval"))
+	_ = viper.BindPFlag("retry_budget", rootCmd.PersistentFlags().Lookup("retry-budget"))
+	_ = viper.BindPFlag("redact", rootCmd.PersistentFlags().Lookup("redact"))
+	_ = viper.BindPFlag("redaction_patterns", rootCmd.PersistentFlags().Lookup("redaction-patterns"))
+	_ = viper.BindPFlag("redaction_high_entropy", rootCmd.PersistentFlags().Lookup("redaction-high-entropy"))
 	_ = viper.BindPFlag("account_id", rootCmd.PersistentFlags().Lookup("account-id"))
 	_ = viper.BindPFlag("org_id", rootCmd.PersistentFlags().Lookup("org-id"))
 	_ = viper.BindPFlag("project_id", rootCmd.PersistentFlags().Lookup("project-id"))
 
+	// Add http specific flags
+	httpCmd.Flags().String("http-addr", ":8080", "Address for the HTTP server to listen on")
+	httpCmd.Flags().String("tls-cert-file", "", "(Optional) path to a TLS certificate file, enables HTTPS when set with --tls-key-file")
+	httpCmd.Flags().String("tls-key-file", "", "(Optional) path to a TLS private key file, enables HTTPS when set with --tls-cert-file")
+	httpCmd.Flags().Duration("shutdown-timeout", 10*time.Second, "Maximum time to wait for in-flight requests when shutting down")
+
+	_ = viper.BindPFlag("http_addr", httpCmd.Flags().Lookup("http-addr"))
+	_ = viper.BindPFlag("tls_cert_file", httpCmd.Flags().Lookup("tls-cert-file"))
+	_ = viper.BindPFlag("tls_key_file", httpCmd.Flags().Lookup("tls-key-file"))
+	_ = viper.BindPFlag("shutdown_timeout", httpCmd.Flags().Lookup("shutdown-timeout"))
+
 	// Add subcommands
 	rootCmd.AddCommand(stdioCmd)
+	rootCmd.AddCommand(httpCmd)
 }
 
 func initConfig() {
@@ -132,18 +302,9 @@ type runConfig struct {
 	enabledToolsets []string
 }
 
-func runStdioServer(config config.Config) error {
-	// Create app context
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
-
-	err := initLogger(config.LogFilePath, config.Debug)
-	if err != nil {
-		return fmt.Errorf("failed to initialize logger: %w", err)
-	}
-
-	slog.Info("Starting server", "url", config.BaseURL)
-
+// newMCPServer creates the Harness MCP server and registers the enabled toolsets with it.
+// It is shared by all the transports.
+func newMCPServer(config config.Config) (*server.MCPServer, error) {
 	// Define beforeInit function to add client info to user agent
 	beforeInit := func(_ context.Context, _ any, message *mcp.InitializeRequest) {
 		slog.Info("Client connected", "name", message.Params.ClientInfo.Name, "version", message.Params.ClientInfo.Version)
@@ -154,25 +315,88 @@ func runStdioServer(config config.Config) error {
 		OnBeforeInitialize: []server.OnBeforeInitializeFunc{beforeInit},
 	}
 
-	// Create server
-	// WithRecovery makes sure panics are logged and don't crash the server
-	harnessServer := harness.NewServer(version, server.WithHooks(hooks), server.WithRecovery())
+	// Create server, NewServer recovers from panics of tool handlers
+	opts := []server.ServerOption{server.WithHooks(hooks)}
+	var redactor *redact.Redactor
+	if config.Redact {
+		var err error
+		redactor, err = redact.New(redact.Options{
+			Patterns:    config.RedactionPatterns,
+			HighEntropy: config.RedactionHighEntropy,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create redactor: %w", err)
+		}
+		opts = append(opts,
+			server.WithToolHandlerMiddleware(harness.RedactionMiddleware(redactor)),
+			server.WithResourceHandlerMiddleware(harness.RedactionResourceMiddleware(redactor)),
+		)
+	}
+	harnessServer := harness.NewServer(version, opts...)
+
+	retryPolicy := client.RetryPolicy{
+		MaxRetries:      config.MaxRetries,
+		InitialInterval: config.RetryInitialInterval,
+		MaxInterval:     config.RetryMaxInterval,
+		MaxElapsedTime:  config.RetryBudget,
+	}
 
-	client, err := client.NewWithToken(config.BaseURL, config.APIKey)
+	client, err := client.NewWithAuthProvider(config.BaseURL, newAuthProvider(config))
 	if err != nil {
 		slog.Error("Failed to create client", "error", err)
-		return fmt.Errorf("failed to create client: %w", err)
+		return nil, fmt.Errorf("failed to create client: %w", err)
 	}
+	client.RetryPolicy = retryPolicy
 
 	// Initialize toolsets
 	toolsets, err := harness.InitToolsets(client, &config)
 	if err != nil {
 		slog.Error("Failed to initialize toolsets", "error", err)
+		return nil, fmt.Errorf("failed to initialize toolsets: %w", err)
 	}
 
 	// Register the tools with the server
 	toolsets.RegisterTools(harnessServer)
 
+	// Register the resources of the enabled toolsets
+	harness.RegisterResources(harnessServer, toolsets, client, &config)
+
+	// Register the prompts of the enabled toolsets
+	harness.RegisterPrompts(harnessServer, toolsets, client, &config, redactor)
+
+	// Poll the subscribed execution and pull request resources and notify the subscribers of changes
+	subscriptions := harness.NewSubscriptions(harnessServer, toolsets, client, &config)
+	subscriptions.AddHooks(hooks)
+
+	// Toolsets enabled at runtime get their resources, prompts and subscriptions registered too
+	if config.DynamicToolsets {
+		harness.InitDynamicToolset(harnessServer, toolsets, func(name string) {
+			harness.RegisterToolsetResources(harnessServer, name, client, &config)
+			harness.RegisterToolsetPrompts(harnessServer, name, client, &config, redactor)
+			subscriptions.AddToolset(name)
+		}).RegisterTools(harnessServer)
+	}
+
+	return harnessServer, nil
+}
+
+func runStdioServer(config config.Config) error {
+	// Create app context
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := initLogger(config.LogFilePath, config.Debug)
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	slog.Info("Starting server", "url", config.BaseURL)
+
+	harnessServer, err := newMCPServer(config)
+	if err != nil {
+		return err
+	}
+
 	// Create stdio server
 	stdioServer := server.NewStdioServer(harnessServer)
 
@@ -182,7 +406,7 @@ func runStdioServer(config config.Config) error {
 	// Start listening for messages
 	errC := make(chan error, 1)
 	go func() {
-		in, out := io.Reader(os.Stdin), io.Writer(os.Stdout)
+		in, out := harness.SubscriptionRequestReader(os.Stdin), io.Writer(os.Stdout)
 
 		errC <- stdioServer.Listen(ctx, in, out)
 	}()
@@ -204,6 +428,91 @@ func runStdioServer(config config.Config) error {
 	return nil
 }
 
+func runHTTPServer(config config.Config) error {
+	// Create app context
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := initLogger(config.LogFilePath, config.Debug)
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return fmt.Errorf("both TLS cert and key files must be provided")
+	}
+
+	slog.Info("Starting server", "url", config.BaseURL)
+	if (config.AuthType == authTypeAPIKey && config.APIKey == "") || (config.AuthType == authTypeBearer && config.BearerToken == "") {
+		slog.Info("No credentials configured, every request must provide its own")
+	}
+
+	harnessServer, err := newMCPServer(config)
+	if err != nil {
+		return err
+	}
+
+	// Both transports share a single listener so clients can pick whichever they support:
+	// the legacy SSE transport on /sse + /message and streamable-HTTP on /mcp.
+	mux := http.NewServeMux()
+	httpServer := &http.Server{
+		Addr:    config.HTTPAddr,
+		Handler: mux,
+	}
+
+	// Credentials sent by the caller on each request are used instead of the configured API key
+	sseServer := server.NewSSEServer(harnessServer,
+		server.WithHTTPServer(httpServer),
+		server.WithKeepAlive(true),
+		server.WithSSEContextFunc(harness.SetupContextFromHTTPRequest),
+	)
+	streamableServer := server.NewStreamableHTTPServer(harnessServer,
+		server.WithStreamableHTTPServer(httpServer),
+		server.WithHTTPContextFunc(harness.SetupContextFromHTTPRequest),
+	)
+
+	mux.Handle(sseServer.CompleteSsePath(), sseServer.SSEHandler())
+	mux.Handle(sseServer.CompleteMessagePath(), harness.SubscriptionRequestMiddleware(sseServer.MessageHandler()))
+	mux.Handle("/mcp", harness.SubscriptionRequestMiddleware(streamableServer))
+
+	// Start listening for requests
+	errC := make(chan error, 1)
+	go func() {
+		if config.TLSCertFile != "" {
+			errC <- httpServer.ListenAndServeTLS(config.TLSCertFile, config.TLSKeyFile)
+			return
+		}
+		errC <- httpServer.ListenAndServe()
+	}()
+
+	// Output startup message
+	slog.Info("Harness MCP Server running on http", "version", version, "address", config.HTTPAddr, "tls", config.TLSCertFile != "")
+
+	// Wait for shutdown signal
+	select {
+	case <-ctx.Done():
+		slog.Info("shutting down server...")
+	case err := <-errC:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("error running server", "error", err)
+			return fmt.Errorf("error running server: %w", err)
+		}
+		return nil
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	// Closing the SSE sessions first lets the long-lived event streams return,
+	// after which the shared HTTP server can drain the remaining requests.
+	if err := sseServer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("error shutting down server", "error", err)
+		return fmt.Errorf("error shutting down server: %w", err)
+	}
+
+	return nil
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -0,0 +1,148 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/harness/harness-mcp/pkg/toolsets"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolsetInfo describes a toolset that can be enabled at runtime
+type toolsetInfo struct {
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	CurrentlyEnabled bool   `json:"currently_enabled"`
+	Tools            int    `json:"tools"`
+}
+
+// toolInfo describes a tool of a toolset
+type toolInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// InitDynamicToolset creates the toolset of the tools discovering and enabling the other toolsets at runtime,
+// used instead of registering all tools up front in dynamic toolsets mode. onEnable is called with the name
+// of each toolset enabled at runtime, once its tools are registered, to register the rest of the toolset.
+func InitDynamicToolset(s *server.MCPServer, tsg *toolsets.ToolsetGroup, onEnable func(name string)) *toolsets.Toolset {
+	dynamic := toolsets.NewToolset("dynamic", "Discover and enable the other toolsets at runtime").
+		AddReadTools(
+			toolsets.NewServerTool(ListAvailableToolsetsTool(tsg)),
+			toolsets.NewServerTool(GetToolsetToolsTool(tsg)),
+		).
+		AddWriteTools(
+			toolsets.NewServerTool(EnableToolsetTool(s, tsg, onEnable)),
+		)
+	dynamic.Enabled = true
+	return dynamic
+}
+
+// ListAvailableToolsetsTool creates a tool for listing the toolsets that can be enabled
+func ListAvailableToolsetsTool(tsg *toolsets.ToolsetGroup) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("list_available_toolsets",
+			mcp.WithDescription("List the toolsets of Harness tools that can be enabled, and whether they are enabled. Use this to find the tools needed for a task, then enable their toolset with enable_toolset."),
+			WithListOutputSchema[toolsetInfo](),
+			WithResponseShaping(),
+			mcp.WithOpenWorldHintAnnotation(false),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			infos := make([]toolsetInfo, 0, len(tsg.Toolsets))
+			for _, name := range toolsetNames(tsg) {
+				toolset := tsg.Toolsets[name]
+				infos = append(infos, toolsetInfo{
+					Name:             toolset.Name,
+					Description:      toolset.Description,
+					CurrentlyEnabled: tsg.IsEnabled(name),
+					Tools:            len(toolset.GetAvailableTools()),
+				})
+			}
+
+			return structuredListResult(infos, "toolsets")
+		}
+}
+
+// GetToolsetToolsTool creates a tool for listing the tools of a toolset before enabling it
+func GetToolsetToolsTool(tsg *toolsets.ToolsetGroup) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("get_toolset_tools",
+			mcp.WithDescription("List the tools of a toolset with their descriptions, to decide whether to enable it."),
+			WithListOutputSchema[toolInfo](),
+			WithResponseShaping(),
+			mcp.WithOpenWorldHintAnnotation(false),
+			mcp.WithString("toolset",
+				mcp.Required(),
+				mcp.Enum(toolsetNames(tsg)...),
+				mcp.Description("The name of the toolset"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			name, err := requiredParam[string](request, "toolset")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			toolset, ok := tsg.Toolsets[name]
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("toolset %s does not exist", name)), nil
+			}
+
+			available := toolset.GetAvailableTools()
+			infos := make([]toolInfo, 0, len(available))
+			for _, tool := range available {
+				infos = append(infos, toolInfo{Name: tool.Tool.Name, Description: tool.Tool.Description})
+			}
+
+			return structuredListResult(infos, "toolset tools")
+		}
+}
+
+// EnableToolsetTool creates a tool for enabling a toolset, which registers its tools with the running server
+// and calls onEnable, if set, to register its resources and prompts. The server notifies the clients that the
+// lists changed. Enabling changes the tools of every session, so dynamic toolsets are only used with stdio.
+func EnableToolsetTool(s *server.MCPServer, tsg *toolsets.ToolsetGroup, onEnable func(name string)) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("enable_toolset",
+			mcp.WithDescription("Enable a toolset, making its tools available. The list of tools is updated once the toolset is enabled."),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(false),
+			mcp.WithString("toolset",
+				mcp.Required(),
+				mcp.Enum(toolsetNames(tsg)...),
+				mcp.Description("The name of the toolset to enable"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			name, err := requiredParam[string](request, "toolset")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			enabled, err := tsg.TryEnableToolset(name)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if !enabled {
+				return mcp.NewToolResultText(fmt.Sprintf("Toolset %s is already enabled", name)), nil
+			}
+
+			tools := tsg.Toolsets[name].GetAvailableTools()
+			s.AddTools(tools...)
+			if onEnable != nil {
+				onEnable(name)
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Toolset %s enabled with %d tools", name, len(tools))), nil
+		}
+}
+
+// toolsetNames returns the names of the toolsets of a group in order.
+func toolsetNames(tsg *toolsets.ToolsetGroup) []string {
+	names := make([]string, 0, len(tsg.Toolsets))
+	for name := range tsg.Toolsets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
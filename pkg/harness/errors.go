@@ -0,0 +1,73 @@
+package harness
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/harness/harness-mcp/client"
+	"github.com/harness/harness-mcp/client/dto"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// apiErrorResult converts an error returned by the Harness client into a tool error result.
+// API errors are explained in terms the model can act on (fix the input, ask for access,
+// retry later) instead of being returned as raw Go errors.
+// action describes what the tool was doing, e.g. "get pipeline".
+func apiErrorResult(err error, action string, scope dto.Scope) *mcp.CallToolResult {
+	return mcp.NewToolResultError(describeAPIError(err, action, scope))
+}
+
+// describeAPIError builds the message used by apiErrorResult.
+func describeAPIError(err error, action string, scope dto.Scope) string {
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		return fmt.Sprintf("failed to %s: %v", action, err)
+	}
+
+	var b strings.Builder
+	switch {
+	case apiErr.StatusCode == http.StatusUnauthorized:
+		fmt.Fprintf(&b, "failed to %s: Harness rejected the credentials (HTTP 401). Check that the API key is valid and has not expired or been revoked.", action)
+	case apiErr.StatusCode == http.StatusForbidden:
+		fmt.Fprintf(&b, "failed to %s: the API key lacks permission to %s in %s (HTTP 403). Ask an administrator to grant the required role, or use a different org_id/project_id.", action, action, describeScope(scope))
+	case apiErr.StatusCode == http.StatusNotFound:
+		fmt.Fprintf(&b, "failed to %s: the requested entity was not found in %s (HTTP 404). Check the identifiers and the org_id/project_id parameters.", action, describeScope(scope))
+	case apiErr.StatusCode == http.StatusConflict:
+		fmt.Fprintf(&b, "failed to %s: the request conflicts with the current state of the entity (HTTP 409). Fetch the latest state before trying again.", action)
+	case apiErr.StatusCode == http.StatusTooManyRequests:
+		fmt.Fprintf(&b, "failed to %s: Harness is rate limiting requests (HTTP 429). Wait before trying again.", action)
+	case apiErr.StatusCode == http.StatusBadRequest:
+		fmt.Fprintf(&b, "failed to %s: Harness rejected the request as invalid (HTTP 400). Check the parameters.", action)
+	case apiErr.StatusCode >= http.StatusInternalServerError:
+		fmt.Fprintf(&b, "failed to %s: Harness returned a server error (HTTP %d). This is usually temporary, try again later.", action, apiErr.StatusCode)
+	default:
+		fmt.Fprintf(&b, "failed to %s: Harness returned HTTP %d.", action, apiErr.StatusCode)
+	}
+
+	if apiErr.Message != "" {
+		fmt.Fprintf(&b, " Harness said: %s", apiErr.Message)
+		if apiErr.Code != "" {
+			fmt.Fprintf(&b, " (%s)", apiErr.Code)
+		}
+		b.WriteString(".")
+	}
+	if apiErr.RequestID != "" {
+		fmt.Fprintf(&b, " Request ID: %s.", apiErr.RequestID)
+	}
+
+	return b.String()
+}
+
+// describeScope returns a human readable description of the scope an operation ran in.
+func describeScope(scope dto.Scope) string {
+	switch {
+	case scope.ProjectID != "":
+		return fmt.Sprintf("project %q (org %q)", scope.ProjectID, scope.OrgID)
+	case scope.OrgID != "":
+		return fmt.Sprintf("org %q", scope.OrgID)
+	default:
+		return fmt.Sprintf("account %q", scope.AccountID)
+	}
+}
@@ -0,0 +1,309 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harness/harness-mcp/client"
+	"github.com/harness/harness-mcp/client/dto"
+	"github.com/harness/harness-mcp/cmd/harness-mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// step types of graph nodes that group other nodes rather than doing work themselves
+var containerStepTypes = map[string]bool{
+	"NG_SECTION":                    true,
+	"NG_SECTION_WITH_ROLLBACK_INFO": true,
+	"NG_EXECUTION":                  true,
+	"NG_FORK":                       true,
+	"STEP_GROUP":                    true,
+	"NG_STAGES_STEP":                true,
+	"PIPELINE_SECTION":              true,
+}
+
+// GetExecutionGraphTool creates a tool for getting the stages and steps of a pipeline execution
+func GetExecutionGraphTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("get_execution_graph",
+			mcp.WithDescription("Get the stages and steps of a pipeline execution in Harness, with their status, start and end timestamps, durations, failure messages and log keys. Use this to find which step of an execution failed."),
+			WithOutputSchema[dto.ExecutionTree](),
+			WithResponseShaping(),
+			mcp.WithString("plan_execution_id",
+				mcp.Required(),
+				mcp.Description("The ID of the plan execution"),
+			),
+			mcp.WithString("stage_id",
+				mcp.Description("Optional stage identifier to only return that stage"),
+			),
+			mcp.WithBoolean("failed_only",
+				mcp.Description("Optional flag to only return failed stages and steps"),
+				mcp.DefaultBool(false),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			planExecutionID, err := requiredParam[string](request, "plan_execution_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			stageID, err := OptionalParam[string](request, "stage_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			failedOnly, err := OptionalParam[bool](request, "failed_only")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			data, err := client.Pipelines.GetExecutionGraph(ctx, scope, planExecutionID)
+			if err != nil {
+				return apiErrorResult(err, "get execution graph", scope), nil
+			}
+
+			tree := buildExecutionTree(data.Data)
+			if stageID != "" {
+				tree.Stages = filterStages(tree.Stages, func(stage dto.StageNode) bool {
+					return stage.Identifier == stageID
+				})
+				if len(tree.Stages) == 0 {
+					return mcp.NewToolResultError(fmt.Sprintf("stage %s not found in execution %s", stageID, planExecutionID)), nil
+				}
+			}
+			if failedOnly {
+				tree.Stages = failedStagesOnly(tree.Stages)
+			}
+
+			return structuredResult(tree, "execution graph")
+		}
+}
+
+// buildExecutionTree turns the layout and the execution graph of an execution into a compact tree of stages and steps.
+func buildExecutionTree(data dto.PipelineExecutionGraphResponse) dto.ExecutionTree {
+	summary := data.PipelineExecutionSummary
+	tree := dto.ExecutionTree{
+		PlanExecutionID:    summary.PlanExecutionId,
+		PipelineIdentifier: summary.PipelineIdentifier,
+		Status:             summary.Status,
+		StartTs:            summary.StartTs,
+		EndTs:              summary.EndTs,
+	}
+
+	stepsByStage := groupStepsByStage(data.ExecutionGraph)
+	for _, layout := range orderedStageLayouts(summary) {
+		tree.Stages = append(tree.Stages, dto.StageNode{
+			Identifier:      layout.NodeIdentifier,
+			Name:            layout.Name,
+			NodeExecutionID: layout.NodeExecutionID,
+			Status:          layout.Status,
+			StartTs:         layout.StartTs,
+			EndTs:           layout.EndTs,
+			DurationMs:      duration(layout.StartTs, layout.EndTs),
+			FailureMessage:  layout.FailureInfo.Message,
+			Steps:           stepsByStage[layout.NodeIdentifier],
+		})
+	}
+
+	return tree
+}
+
+// orderedStageLayouts returns the stage layout nodes in execution order, expanding groups of parallel stages.
+func orderedStageLayouts(summary dto.PipelineExecutionLayout) []dto.LayoutNode {
+	var stages []dto.LayoutNode
+	visited := make(map[string]bool)
+
+	var visit func(id string)
+	visit = func(id string) {
+		node, ok := summary.LayoutNodeMap[id]
+		if !ok || visited[id] {
+			return
+		}
+		visited[id] = true
+
+		if strings.EqualFold(node.NodeType, "parallel") {
+			for _, child := range node.EdgeLayoutList.CurrentNodeChildren {
+				visit(child)
+			}
+		} else {
+			stages = append(stages, node)
+		}
+		for _, next := range node.EdgeLayoutList.NextIDs {
+			visit(next)
+		}
+	}
+	visit(summary.StartingNodeID)
+
+	// stages that are not reachable from the starting node are appended in a stable order
+	var remaining []string
+	for id := range summary.LayoutNodeMap {
+		if !visited[id] {
+			remaining = append(remaining, id)
+		}
+	}
+	sort.Strings(remaining)
+	for _, id := range remaining {
+		visit(id)
+	}
+
+	return stages
+}
+
+// groupStepsByStage collects the steps of the execution graph keyed by the identifier of their stage.
+// Steps are the leaves of the graph, their stage is found from their fully qualified name.
+func groupStepsByStage(graph dto.ExecutionGraph) map[string][]dto.StepNode {
+	stepsByStage := make(map[string][]dto.StepNode)
+	for id, node := range graph.NodeMap {
+		if len(graph.NodeAdjacencyListMap[id].Children) > 0 || containerStepTypes[node.StepType] {
+			continue
+		}
+
+		stageID, ok := stageFromFqn(node.BaseFqn)
+		if !ok || node.BaseFqn == "pipeline.stages."+stageID {
+			continue
+		}
+
+		stepsByStage[stageID] = append(stepsByStage[stageID], dto.StepNode{
+			Identifier:      node.Identifier,
+			Name:            node.Name,
+			NodeExecutionID: node.UUID,
+			StepType:        node.StepType,
+			Status:          node.Status,
+			StartTs:         node.StartTs,
+			EndTs:           node.EndTs,
+			DurationMs:      duration(node.StartTs, node.EndTs),
+			FailureMessage:  node.FailureInfo.Message,
+			FailureTypes:    node.FailureInfo.FailureTypeList,
+			LogKeys:         logKeys(node),
+		})
+	}
+
+	// order steps by start time, steps that did not start go last
+	for _, steps := range stepsByStage {
+		sort.SliceStable(steps, func(i, j int) bool {
+			if (steps[i].StartTs == 0) != (steps[j].StartTs == 0) {
+				return steps[j].StartTs == 0
+			}
+			if steps[i].StartTs != steps[j].StartTs {
+				return steps[i].StartTs < steps[j].StartTs
+			}
+			return steps[i].Name < steps[j].Name
+		})
+	}
+
+	return stepsByStage
+}
+
+// stageFromFqn extracts the stage identifier from a fully qualified name like
+// pipeline.stages.build.spec.execution.steps.run_tests
+func stageFromFqn(fqn string) (string, bool) {
+	rest, ok := strings.CutPrefix(fqn, "pipeline.stages.")
+	if !ok || rest == "" {
+		return "", false
+	}
+	stageID, _, _ := strings.Cut(rest, ".")
+	return stageID, true
+}
+
+// logKeys returns the keys the log service stores the logs of a node under.
+func logKeys(node dto.ExecutionNode) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, response := range node.ExecutableResponses {
+		for _, executable := range []*dto.ExecutableLogKeys{response.Async, response.Sync, response.Task, response.TaskChain} {
+			if executable == nil {
+				continue
+			}
+			for _, key := range executable.LogKeys {
+				if !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			}
+		}
+	}
+	if len(keys) == 0 && node.LogBaseKey != "" {
+		keys = append(keys, node.LogBaseKey)
+	}
+	return keys
+}
+
+// failedStagesOnly keeps the failed stages and, within them, the failed steps.
+func failedStagesOnly(stages []dto.StageNode) []dto.StageNode {
+	var failed []dto.StageNode
+	for _, stage := range stages {
+		var steps []dto.StepNode
+		for _, step := range stage.Steps {
+			if isFailedStatus(step.Status) {
+				steps = append(steps, step)
+			}
+		}
+		if isFailedStatus(stage.Status) || len(steps) > 0 {
+			stage.Steps = steps
+			failed = append(failed, stage)
+		}
+	}
+	return failed
+}
+
+// filterStages returns the stages matching keep.
+func filterStages(stages []dto.StageNode, keep func(dto.StageNode) bool) []dto.StageNode {
+	var filtered []dto.StageNode
+	for _, stage := range stages {
+		if keep(stage) {
+			filtered = append(filtered, stage)
+		}
+	}
+	return filtered
+}
+
+// duration returns the time in milliseconds between two timestamps, or 0 if either is unknown.
+func duration(startTs, endTs int64) int64 {
+	if startTs == 0 || endTs == 0 || endTs < startTs {
+		return 0
+	}
+	return endTs - startTs
+}
+
+// findStep finds a step of the execution tree by identifier or node execution ID, optionally within a single stage.
+// Without a step ID, the first step whose status matches defaultStatus is returned, described by defaultDesc in errors.
+func findStep(tree dto.ExecutionTree, stageID, stepID string, defaultStatus func(string) bool, defaultDesc string) (dto.StageNode, dto.StepNode, error) {
+	var matches []dto.StageNode
+	for _, stage := range tree.Stages {
+		if stageID != "" && stage.Identifier != stageID {
+			continue
+		}
+		for _, step := range stage.Steps {
+			found := step.Identifier == stepID || step.NodeExecutionID == stepID
+			if stepID == "" {
+				found = defaultStatus(step.Status)
+			}
+			if found {
+				stage.Steps = []dto.StepNode{step}
+				matches = append(matches, stage)
+			}
+		}
+	}
+
+	switch {
+	case len(matches) == 1 || (len(matches) > 1 && stepID == ""):
+		return matches[0], matches[0].Steps[0], nil
+	case len(matches) > 1:
+		var stages []string
+		for _, stage := range matches {
+			stages = append(stages, stage.Identifier)
+		}
+		return dto.StageNode{}, dto.StepNode{}, fmt.Errorf("step %s exists in several stages (%s), pass a stage_id to select one", stepID, strings.Join(stages, ", "))
+	case stepID == "":
+		return dto.StageNode{}, dto.StepNode{}, fmt.Errorf("no %s step found in execution %s, pass a step_id to select one", defaultDesc, tree.PlanExecutionID)
+	default:
+		return dto.StageNode{}, dto.StepNode{}, fmt.Errorf("step %s not found in execution %s", stepID, tree.PlanExecutionID)
+	}
+}
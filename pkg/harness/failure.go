@@ -0,0 +1,400 @@
+package harness
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/harness/harness-mcp/client"
+	"github.com/harness/harness-mcp/client/dto"
+	"github.com/harness/harness-mcp/cmd/harness-mcp-server/config"
+	"github.com/harness/harness-mcp/pkg/textutil"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	defaultFailureSnippets = 10
+	maxFailureSnippets     = 50
+	// number of failed steps whose logs are analyzed
+	maxAnalyzedSteps = 3
+	// number of distinct candidate snippets kept per step log
+	maxSnippetCandidates = 200
+	// number of further occurrences of a snippet that are referenced by line
+	maxSnippetLineRefs = 5
+	maxSnippetLineLen  = 500
+)
+
+// failurePattern recognizes log lines that are likely to explain a failure.
+type failurePattern struct {
+	category string
+	// lines following a match that are kept with it
+	contextLines int
+	re           *regexp.Regexp
+}
+
+// failurePatterns are checked in order, the first matching pattern decides the category of a line.
+// Categories listed first are more specific and more likely to be the root cause.
+var failurePatterns = []failurePattern{
+	{"oom_killed", 1, regexp.MustCompile(`(?i)OOMKilled|out of memory|OutOfMemoryError|heap out of memory|cannot allocate memory|memory limit exceeded|Killed process \d+|exit(ed with)? code:? ?137\b`)},
+	{"image_pull", 1, regexp.MustCompile(`(?i)ErrImagePull|ImagePullBackOff|pull access denied|manifest unknown|failed to pull image|error pulling image|toomanyrequests: .*pull rate limit|repository does not exist or may require`)},
+	{"compiler_error", 2, regexp.MustCompile(`(?i)^\S+\.(go|java|kt|scala|ts|tsx|js|jsx|py|c|cc|cpp|h|hpp|cs|rs|swift)(:\d+(:\d+)?:|\(\d+,\d+\)|:\[\d+,\d+\])\s*(error|fatal)?|^error\[E\d+\]:|COMPILATION ERROR|cannot find symbol|error TS\d+|SyntaxError:|build failed`)},
+	{"test_failure", 2, regexp.MustCompile(`(?i)^\s*--- FAIL:|^FAIL\s|Tests run: \d+, Failures: [1-9]|Tests run: \d+, Failures: \d+, Errors: [1-9]|^\s*FAILED\s|\b[1-9]\d* (failed|failing)\b|AssertionError|assert(ion)? failed|\bexpected\b.+\b(but|got)\b`)},
+	{"stack_trace", 3, regexp.MustCompile(`^Traceback \(most recent call last\)|^panic: |^fatal error: |^Exception in thread|^(Caused by: )?([a-zA-Z_$][\w$]*\.)+[A-Z][\w$]*(Exception|Error)(: |$)|^Unhandled(PromiseRejection)?|^\s*Error: .+`)},
+	{"exit_code", 0, regexp.MustCompile(`(?i)exit(ed with)? (code|status):? ?[1-9]\d*|non-zero (exit )?code|returned a non-zero code|command terminated with exit code|signal: killed`)},
+	{"error", 0, regexp.MustCompile(`(?i)^\s*(\[?(error|fatal)\]?[:\s]|err:)|\b(error|fatal):`)},
+}
+
+// categoryRank orders snippet categories from most to least likely root cause.
+var categoryRank = func() map[string]int {
+	rank := make(map[string]int)
+	for i, p := range failurePatterns {
+		rank[p.category] = i
+	}
+	return rank
+}()
+
+var (
+	// parts of a line that vary between otherwise identical lines
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	numberPattern    = regexp.MustCompile(`0x[0-9a-fA-F]+|[0-9a-fA-F]{8,}|\d+`)
+)
+
+// failureMessage is a failure message reported by Harness for the execution or one of its nodes.
+type failureMessage struct {
+	Source  string `json:"source"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// failureSnippet is a probable root cause found in the log of a failed step.
+type failureSnippet struct {
+	Category    string   `json:"category"`
+	StageID     string   `json:"stage_id,omitempty"`
+	StepID      string   `json:"step_id,omitempty"`
+	Line        int      `json:"line,omitempty"`
+	Text        string   `json:"text"`
+	Context     []string `json:"context,omitempty"`
+	Occurrences int      `json:"occurrences"`
+	OtherLines  []int    `json:"other_lines,omitempty"`
+
+	key   string
+	order int
+}
+
+// failedStepSummary describes a failed step whose log was analyzed.
+type failedStepSummary struct {
+	StageID        string `json:"stage_id"`
+	StepID         string `json:"step_id"`
+	StepName       string `json:"step_name,omitempty"`
+	StepType       string `json:"step_type,omitempty"`
+	Status         string `json:"status"`
+	FailureMessage string `json:"failure_message,omitempty"`
+	LogLines       int    `json:"log_lines"`
+	LogError       string `json:"log_error,omitempty"`
+}
+
+// failureSummary is the result of analyzing a failed execution.
+type failureSummary struct {
+	PlanExecutionID    string              `json:"plan_execution_id"`
+	PipelineIdentifier string              `json:"pipeline_identifier"`
+	Status             string              `json:"status"`
+	FailureMessages    []failureMessage    `json:"failure_messages,omitempty"`
+	FailedSteps        []failedStepSummary `json:"failed_steps,omitempty"`
+	RootCauses         []failureSnippet    `json:"root_causes"`
+}
+
+// SummarizeExecutionFailureTool creates a tool for finding the probable root cause of a failed pipeline execution
+func SummarizeExecutionFailureTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("summarize_execution_failure",
+			mcp.WithDescription("Summarize why a pipeline execution in Harness failed. Combines the failure messages reported by Harness with an analysis of the logs of the failed steps, looking for compiler errors, test failures, exit codes, stack traces, OOM kills and image pull errors. Returns a deduplicated list of probable root causes, most likely first, with the log lines they were found on."),
+			WithOutputSchema[failureSummary](),
+			WithResponseShaping(),
+			mcp.WithString("plan_execution_id",
+				mcp.Required(),
+				mcp.Description("The ID of the plan execution"),
+			),
+			mcp.WithString("stage_id",
+				mcp.Description("Optional stage identifier to only analyze the failed steps of that stage"),
+			),
+			mcp.WithString("step_id",
+				mcp.Description("Optional step identifier to only analyze the log of that step"),
+			),
+			mcp.WithNumber("max_snippets",
+				mcp.DefaultNumber(defaultFailureSnippets),
+				mcp.Max(maxFailureSnippets),
+				mcp.Description("Maximum number of root cause snippets to return"),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			planExecutionID, err := requiredParam[string](request, "plan_execution_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			stageID, err := OptionalParam[string](request, "stage_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			stepID, err := OptionalParam[string](request, "step_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			maxSnippets, err := OptionalIntParamWithDefault(request, "max_snippets", defaultFailureSnippets)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			maxSnippets = min(max(maxSnippets, 1), maxFailureSnippets)
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			data, err := client.Pipelines.GetExecutionGraph(ctx, scope, planExecutionID)
+			if err != nil {
+				return apiErrorResult(err, "get execution graph", scope), nil
+			}
+
+			summary := summarizeExecutionFailure(ctx, client, scope, data.Data, stageID, stepID, maxSnippets)
+
+			return structuredResult(summary, "failure summary")
+		}
+}
+
+// summarizeExecutionFailure analyzes the failure messages of an execution and the logs of up to
+// maxAnalyzedSteps failed steps, optionally limited to a stage or step.
+func summarizeExecutionFailure(ctx context.Context, client *client.Client, scope dto.Scope, data dto.PipelineExecutionGraphResponse, stageID, stepID string, maxSnippets int) failureSummary {
+	tree := buildExecutionTree(data)
+	summary := failureSummary{
+		PlanExecutionID:    tree.PlanExecutionID,
+		PipelineIdentifier: tree.PipelineIdentifier,
+		Status:             tree.Status,
+		FailureMessages:    failureMessages(data),
+	}
+
+	var snippets []failureSnippet
+	for _, stage := range failedStagesOnly(tree.Stages) {
+		if stageID != "" && stage.Identifier != stageID {
+			continue
+		}
+		for _, step := range stage.Steps {
+			if stepID != "" && step.Identifier != stepID && step.NodeExecutionID != stepID {
+				continue
+			}
+			if len(summary.FailedSteps) >= maxAnalyzedSteps {
+				break
+			}
+
+			stepSummary := failedStepSummary{
+				StageID:        stage.Identifier,
+				StepID:         step.Identifier,
+				StepName:       step.Name,
+				StepType:       step.StepType,
+				Status:         step.Status,
+				FailureMessage: step.FailureMessage,
+			}
+
+			analyzer := newFailureAnalyzer(stage.Identifier, step.Identifier)
+			for _, key := range step.LogKeys {
+				if err := client.Logs.StreamLog(ctx, scope, key, analyzer.add); err != nil {
+					stepSummary.LogError = describeAPIError(err, "get step logs", scope)
+					break
+				}
+			}
+			stepSummary.LogLines = analyzer.lines
+			snippets = append(snippets, analyzer.snippets()...)
+			summary.FailedSteps = append(summary.FailedSteps, stepSummary)
+		}
+	}
+
+	summary.RootCauses = rankFailureSnippets(snippets, maxSnippets)
+
+	return summary
+}
+
+// failureMessages collects the deduplicated failure messages of the execution and its failed nodes.
+func failureMessages(data dto.PipelineExecutionGraphResponse) []failureMessage {
+	var messages []failureMessage
+	seen := make(map[string]bool)
+	add := func(source string, responses []dto.ExecutionResponseMessage) {
+		for _, response := range responses {
+			message := response.Message
+			if message == "" {
+				message = response.Exception.Message
+			}
+			key := normalizeFailureLine(message)
+			if message == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			messages = append(messages, failureMessage{Source: source, Code: response.Code, Message: message})
+		}
+	}
+
+	add("execution", data.PipelineExecutionSummary.FailureInfo.ResponseMessages)
+
+	// nodes are visited in a stable order so that the source of duplicated messages is deterministic
+	ids := make([]string, 0, len(data.ExecutionGraph.NodeMap))
+	for id := range data.ExecutionGraph.NodeMap {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := data.ExecutionGraph.NodeMap[ids[i]], data.ExecutionGraph.NodeMap[ids[j]]
+		if a.StartTs != b.StartTs {
+			return a.StartTs < b.StartTs
+		}
+		return ids[i] < ids[j]
+	})
+	for _, id := range ids {
+		node := data.ExecutionGraph.NodeMap[id]
+		if isFailedStatus(node.Status) {
+			add(node.BaseFqn, node.FailureInfo.ResponseMessages)
+		}
+	}
+
+	return messages
+}
+
+// failureAnalyzer scans the log of a step for lines matching the failure patterns.
+type failureAnalyzer struct {
+	stageID string
+	stepID  string
+	lines   int
+
+	candidates map[string]*failureSnippet
+	// snippets still collecting their context lines
+	pending []*failureSnippet
+}
+
+func newFailureAnalyzer(stageID, stepID string) *failureAnalyzer {
+	return &failureAnalyzer{
+		stageID:    stageID,
+		stepID:     stepID,
+		candidates: make(map[string]*failureSnippet),
+	}
+}
+
+// add analyzes the next line of the log, it has the signature of the log service callbacks.
+func (a *failureAnalyzer) add(line dto.LogLine) bool {
+	a.lines++
+	text := truncateLine(strings.TrimRight(line.Out, "\r\n"))
+
+	open := a.pending[:0]
+	for _, snippet := range a.pending {
+		snippet.Context = append(snippet.Context, text)
+		if len(snippet.Context) < failurePatternFor(snippet.Category).contextLines {
+			open = append(open, snippet)
+		}
+	}
+	a.pending = open
+
+	category, ok := classifyFailureLine(text)
+	if !ok {
+		return true
+	}
+
+	key := category + "\x00" + normalizeFailureLine(text)
+	if snippet, ok := a.candidates[key]; ok {
+		snippet.Occurrences++
+		if len(snippet.OtherLines) < maxSnippetLineRefs {
+			snippet.OtherLines = append(snippet.OtherLines, a.lines)
+		}
+		return true
+	}
+	if len(a.candidates) >= maxSnippetCandidates {
+		return true
+	}
+
+	snippet := &failureSnippet{
+		Category:    category,
+		StageID:     a.stageID,
+		StepID:      a.stepID,
+		Line:        a.lines,
+		Text:        strings.TrimSpace(text),
+		Occurrences: 1,
+		key:         key,
+		order:       len(a.candidates),
+	}
+	a.candidates[key] = snippet
+	if failurePatternFor(category).contextLines > 0 {
+		a.pending = append(a.pending, snippet)
+	}
+	return true
+}
+
+// snippets returns the snippets found so far in the order they appear in the log.
+func (a *failureAnalyzer) snippets() []failureSnippet {
+	snippets := make([]failureSnippet, 0, len(a.candidates))
+	for _, snippet := range a.candidates {
+		snippets = append(snippets, *snippet)
+	}
+	sort.Slice(snippets, func(i, j int) bool {
+		return snippets[i].order < snippets[j].order
+	})
+	return snippets
+}
+
+// classifyFailureLine returns the category of the first failure pattern matching line.
+func classifyFailureLine(line string) (string, bool) {
+	if strings.TrimSpace(line) == "" {
+		return "", false
+	}
+	for _, pattern := range failurePatterns {
+		if pattern.re.MatchString(line) {
+			return pattern.category, true
+		}
+	}
+	return "", false
+}
+
+func failurePatternFor(category string) failurePattern {
+	for _, pattern := range failurePatterns {
+		if pattern.category == category {
+			return pattern
+		}
+	}
+	return failurePattern{}
+}
+
+// rankFailureSnippets orders snippets by category, keeping the order of snippets of the same category,
+// and returns at most limit snippets. Snippets repeated across steps are merged.
+func rankFailureSnippets(snippets []failureSnippet, limit int) []failureSnippet {
+	sort.SliceStable(snippets, func(i, j int) bool {
+		return categoryRank[snippets[i].Category] < categoryRank[snippets[j].Category]
+	})
+
+	ranked := []failureSnippet{}
+	seen := make(map[string]int)
+	for _, snippet := range snippets {
+		if i, ok := seen[snippet.key]; ok {
+			ranked[i].Occurrences += snippet.Occurrences
+			continue
+		}
+		if len(ranked) >= limit {
+			continue
+		}
+		seen[snippet.key] = len(ranked)
+		ranked = append(ranked, snippet)
+	}
+	return ranked
+}
+
+// normalizeFailureLine reduces a line to a form that is the same for lines differing only in timestamps, numbers or spacing.
+func normalizeFailureLine(line string) string {
+	line = timestampPattern.ReplaceAllString(line, "")
+	line = numberPattern.ReplaceAllString(line, "#")
+	return strings.ToLower(strings.Join(strings.Fields(line), " "))
+}
+
+// truncateLine shortens overly long log lines, cutting them at a character boundary.
+func truncateLine(line string) string {
+	if len(line) <= maxSnippetLineLen {
+		return line
+	}
+	return line[:textutil.RunePrefixLen(line, maxSnippetLineLen)] + "..."
+}
@@ -0,0 +1,536 @@
+package harness
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/harness/harness-mcp/client"
+	"github.com/harness/harness-mcp/client/dto"
+)
+
+// limits applied when downloading and extracting a log archive, so that a corrupt or malicious archive cannot fill the disk
+const (
+	maxLogArchiveEntries = 10000
+	maxLogEntrySize      = 256 * 1024 * 1024
+	maxLogArchiveSize    = 1024 * 1024 * 1024
+	maxLogDownloadSize   = maxLogArchiveSize
+)
+
+// logs of executions searched with search_execution_logs are removed once unused for logCacheTTL,
+// or when more than maxCachedExecutions are cached
+const (
+	logCacheTTL         = time.Hour
+	maxCachedExecutions = 20
+)
+
+const (
+	logArchiveName = "logs.zip"
+	logIndexName   = "index.json"
+	logFilesDir    = "logs"
+)
+
+var errLogArchiveTooLarge = errors.New("log archive exceeds the size limit")
+
+// logIndex describes the extracted logs of an execution, one entry per log.
+type logIndex struct {
+	PlanExecutionID string          `json:"plan_execution_id"`
+	Status          string          `json:"status"`
+	Entries         []logIndexEntry `json:"entries"`
+}
+
+// logIndexEntry describes the extracted log of a single step.
+type logIndexEntry struct {
+	StageID  string `json:"stage_id,omitempty"`
+	StepID   string `json:"step_id,omitempty"`
+	StepName string `json:"step_name,omitempty"`
+	Key      string `json:"key"`
+	Path     string `json:"path"`
+	Lines    int    `json:"lines"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// logMatch is a line of a log matching a search, with the lines around it.
+type logMatch struct {
+	Line   int      `json:"line"`
+	Text   string   `json:"text"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// stepLogMatches groups the matches found in the log of a step.
+type stepLogMatches struct {
+	StageID  string     `json:"stage_id,omitempty"`
+	StepID   string     `json:"step_id,omitempty"`
+	StepName string     `json:"step_name,omitempty"`
+	Matches  []logMatch `json:"matches"`
+}
+
+// logSearchResult is the result of searching the logs of an execution.
+type logSearchResult struct {
+	PlanExecutionID string           `json:"plan_execution_id"`
+	Query           string           `json:"query"`
+	SearchedLogs    int              `json:"searched_logs"`
+	TotalMatches    int              `json:"total_matches"`
+	Truncated       bool             `json:"truncated,omitempty"`
+	Steps           []stepLogMatches `json:"steps"`
+}
+
+// downloadLogArchive downloads the logs of an execution as a zip archive to path and returns its size.
+// The file is only readable by the current user, and archives larger than maxLogDownloadSize are rejected.
+func downloadLogArchive(ctx context.Context, client *client.Client, scope dto.Scope, planExecutionID string, path string) (int64, error) {
+	logDownloadURL, err := client.Logs.DownloadLogs(ctx, scope, planExecutionID)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, logDownloadURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create log download request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download logs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to download logs: unexpected status code %d", resp.StatusCode)
+	}
+
+	outputFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer outputFile.Close()
+
+	// copy one byte past the limit to tell an archive of exactly the limit from a larger one
+	bytesWritten, err := io.Copy(outputFile, io.LimitReader(resp.Body, maxLogDownloadSize+1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to write logs to file: %w", err)
+	}
+	if bytesWritten > maxLogDownloadSize {
+		return 0, fmt.Errorf("%w: the download is larger than %d bytes", errLogArchiveTooLarge, int64(maxLogDownloadSize))
+	}
+	return bytesWritten, nil
+}
+
+// extractLogArchive extracts the log archive at zipPath into destDir as plain text, one file per log.
+// Entries that would be written outside destDir are rejected, and the number of entries and
+// their uncompressed size are limited regardless of what the archive headers claim.
+// The extracted logs are only readable by the current user.
+func extractLogArchive(zipPath, destDir string) ([]logIndexEntry, error) {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log archive: %w", err)
+	}
+	defer r.Close()
+
+	if len(r.File) > maxLogArchiveEntries {
+		return nil, fmt.Errorf("log archive has %d entries, more than the limit of %d", len(r.File), maxLogArchiveEntries)
+	}
+
+	var entries []logIndexEntry
+	var total int64
+	for _, f := range r.File {
+		// only regular files are extracted, directories are created as needed and symlinks are skipped
+		if !f.Mode().IsRegular() {
+			continue
+		}
+
+		name := sanitizeLogEntryName(f.Name)
+		if !filepath.IsLocal(name) {
+			return nil, fmt.Errorf("log archive entry %q points outside of the extraction directory", f.Name)
+		}
+
+		target := filepath.Join(destDir, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return nil, fmt.Errorf("failed to create log directory: %w", err)
+		}
+
+		entry, err := extractLogEntry(f, target, min(maxLogEntrySize, maxLogArchiveSize-total))
+		if err != nil {
+			return nil, err
+		}
+		entry.Key = strings.TrimPrefix(f.Name, "/")
+		entry.Path = name
+		total += entry.Bytes
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// extractLogEntry writes the output of the log lines of an archive entry to target, reading at most limit bytes.
+func extractLogEntry(f *zip.File, target string, limit int64) (logIndexEntry, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return logIndexEntry{}, fmt.Errorf("failed to open log archive entry %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return logIndexEntry{}, fmt.Errorf("failed to create log file: %w", err)
+	}
+	defer out.Close()
+
+	// read one byte past the limit to tell a log of exactly limit bytes from a larger one
+	in := &countingReader{r: io.LimitReader(rc, limit+1)}
+	w := bufio.NewWriter(out)
+
+	var entry logIndexEntry
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), client.MaxLogLineSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		line := client.ParseLogLine(scanner.Bytes())
+		if _, err := w.WriteString(strings.TrimRight(line.Out, "\r\n") + "\n"); err != nil {
+			return logIndexEntry{}, fmt.Errorf("failed to write log file: %w", err)
+		}
+		entry.Lines++
+	}
+	if err := scanner.Err(); err != nil {
+		return logIndexEntry{}, fmt.Errorf("failed to read log archive entry %s: %w", f.Name, err)
+	}
+	if in.n > limit {
+		return logIndexEntry{}, fmt.Errorf("%w: entry %s", errLogArchiveTooLarge, f.Name)
+	}
+	if err := w.Flush(); err != nil {
+		return logIndexEntry{}, fmt.Errorf("failed to write log file: %w", err)
+	}
+
+	entry.Bytes = in.n
+	return entry, nil
+}
+
+// sanitizeLogEntryName turns the name of an archive entry, which is a log key, into a relative file path.
+// Characters that are not portable in file names, like the colons of log keys, are replaced.
+func sanitizeLogEntryName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '/':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	return filepath.FromSlash(strings.TrimPrefix(name, "/"))
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// assignLogEntries fills in the stage and step of the index entries. Entries are matched to the steps of the
+// execution tree by log key, falling back to the stage and step identifiers that are part of the key.
+func assignLogEntries(entries []logIndexEntry, tree dto.ExecutionTree) {
+	type owner struct {
+		stage dto.StageNode
+		step  dto.StepNode
+	}
+	owners := make(map[string]owner)
+	for _, stage := range tree.Stages {
+		for _, step := range stage.Steps {
+			for _, key := range step.LogKeys {
+				owners[key] = owner{stage: stage, step: step}
+			}
+		}
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		o, ok := owners[entry.Key]
+		if !ok {
+			// logs of a step may be split into several keys sharing the step's key as prefix
+			for key, candidate := range owners {
+				if strings.HasPrefix(entry.Key, key+"-") || strings.HasPrefix(entry.Key, key+"/") {
+					o, ok = candidate, true
+					break
+				}
+			}
+		}
+		if ok {
+			entry.StageID, entry.StepID, entry.StepName = o.stage.Identifier, o.step.Identifier, o.step.Name
+		} else {
+			entry.StageID, entry.StepID = stageAndStepFromLogKey(entry.Key)
+		}
+	}
+}
+
+// stageAndStepFromLogKey extracts the stage and step identifiers from a log key like
+// accountId:a/orgId:o/projectId:p/pipelineId:p/runSequence:1/level0:pipeline/level1:stages/level2:build/.../level5:run_tests
+func stageAndStepFromLogKey(key string) (string, string) {
+	var levels []string
+	for _, segment := range strings.Split(key, "/") {
+		name, value, ok := strings.Cut(segment, ":")
+		if ok && strings.HasPrefix(name, "level") {
+			levels = append(levels, value)
+		}
+	}
+
+	var stageID, stepID string
+	for i, level := range levels {
+		if level == "stages" && i+1 < len(levels) {
+			stageID = levels[i+1]
+			break
+		}
+	}
+	if len(levels) > 0 {
+		stepID = levels[len(levels)-1]
+	}
+	return stageID, stepID
+}
+
+// prepareExecutionLogs downloads, extracts and indexes the logs of an execution in dir, which the caller
+// must hold locked, see logCache. Logs of a finished execution that were prepared before are reused unless
+// refresh is set. The execution is always read from Harness first, so that cached logs are only returned
+// to callers allowed to read them.
+func prepareExecutionLogs(ctx context.Context, client *client.Client, scope dto.Scope, planExecutionID, dir string, refresh bool) (logIndex, error) {
+	graph, err := client.Pipelines.GetExecutionGraph(ctx, scope, planExecutionID)
+	if err != nil {
+		return logIndex{}, err
+	}
+	tree := buildExecutionTree(graph.Data)
+
+	indexPath := filepath.Join(dir, logIndexName)
+	if !refresh {
+		if index, err := readLogIndex(indexPath); err == nil && isTerminalStatus(index.Status) {
+			return index, nil
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return logIndex{}, fmt.Errorf("failed to create logs folder: %w", err)
+	}
+	zipPath := filepath.Join(dir, logArchiveName)
+	if _, err := downloadLogArchive(ctx, client, scope, planExecutionID, zipPath); err != nil {
+		return logIndex{}, err
+	}
+
+	filesDir := filepath.Join(dir, logFilesDir)
+	if err := os.RemoveAll(filesDir); err != nil {
+		return logIndex{}, fmt.Errorf("failed to clean up logs folder: %w", err)
+	}
+	entries, err := extractLogArchive(zipPath, filesDir)
+	if err != nil {
+		return logIndex{}, err
+	}
+	assignLogEntries(entries, tree)
+
+	index := logIndex{
+		PlanExecutionID: planExecutionID,
+		Status:          tree.Status,
+		Entries:         entries,
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return logIndex{}, fmt.Errorf("failed to marshal log index: %w", err)
+	}
+	if err := os.WriteFile(indexPath, data, 0600); err != nil {
+		return logIndex{}, fmt.Errorf("failed to write log index: %w", err)
+	}
+
+	return index, nil
+}
+
+// readLogIndex reads a log index written by prepareExecutionLogs.
+func readLogIndex(path string) (logIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return logIndex{}, err
+	}
+	var index logIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return logIndex{}, err
+	}
+	return index, nil
+}
+
+// logCache keeps the logs of executions prepared for searching in a directory private to the process.
+// The logs of an execution are kept per account, org and project, and are prepared and read under a
+// lock of the execution. They are removed once unused for logCacheTTL, or when too many are cached.
+type logCache struct {
+	mu      sync.Mutex
+	root    string
+	entries map[string]*logCacheEntry
+}
+
+// logCacheEntry tracks the use of the logs of an execution.
+type logCacheEntry struct {
+	mu       sync.Mutex
+	refs     int
+	lastUsed time.Time
+}
+
+// executionLogs caches the logs searched with search_execution_logs.
+var executionLogs = &logCache{}
+
+// acquire locks the logs of an execution and returns their directory, with a function unlocking them.
+func (c *logCache) acquire(scope dto.Scope, planExecutionID string) (string, func(), error) {
+	if !filepath.IsLocal(planExecutionID) || strings.ContainsAny(planExecutionID, `/\`) {
+		return "", nil, fmt.Errorf("invalid plan execution ID: %s", planExecutionID)
+	}
+
+	c.mu.Lock()
+	if c.root == "" {
+		root, err := os.MkdirTemp("", "harness-mcp-logs-")
+		if err != nil {
+			c.mu.Unlock()
+			return "", nil, fmt.Errorf("failed to create logs cache folder: %w", err)
+		}
+		c.root = root
+		c.entries = make(map[string]*logCacheEntry)
+	}
+
+	dir := filepath.Join(c.root, logCacheScopeKey(scope), planExecutionID)
+	entry, ok := c.entries[dir]
+	if !ok {
+		entry = &logCacheEntry{}
+		c.entries[dir] = entry
+	}
+	entry.refs++
+	c.evict(time.Now())
+	c.mu.Unlock()
+
+	entry.mu.Lock()
+	return dir, func() {
+		entry.mu.Unlock()
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		entry.refs--
+		entry.lastUsed = time.Now()
+	}, nil
+}
+
+// evict removes the logs of executions that are not in use and were last used more than logCacheTTL ago,
+// then the least recently used ones above maxCachedExecutions. c.mu must be held.
+func (c *logCache) evict(now time.Time) {
+	var unused []string
+	for dir, entry := range c.entries {
+		if entry.refs == 0 {
+			unused = append(unused, dir)
+		}
+	}
+	slices.SortFunc(unused, func(a, b string) int {
+		return c.entries[a].lastUsed.Compare(c.entries[b].lastUsed)
+	})
+
+	excess := len(c.entries) - maxCachedExecutions
+	for i, dir := range unused {
+		if i >= excess && now.Sub(c.entries[dir].lastUsed) <= logCacheTTL {
+			break
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			slog.Warn("Failed to remove cached execution logs", "dir", dir, "error", err)
+			continue
+		}
+		delete(c.entries, dir)
+	}
+}
+
+// logCacheScopeKey returns the name of the cache folder of the executions of an account, org and project.
+func logCacheScopeKey(scope dto.Scope) string {
+	sum := sha256.Sum256([]byte(scope.AccountID + "/" + scope.OrgID + "/" + scope.ProjectID))
+	return hex.EncodeToString(sum[:16])
+}
+
+// newLogMatcher returns a function reporting whether a line matches query, a regular expression or a keyword.
+func newLogMatcher(query string, isRegex, caseSensitive bool) (func(string) bool, error) {
+	if isRegex {
+		if !caseSensitive {
+			query = "(?i)" + query
+		}
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		return re.MatchString, nil
+	}
+
+	if caseSensitive {
+		return func(line string) bool { return strings.Contains(line, query) }, nil
+	}
+	query = strings.ToLower(query)
+	return func(line string) bool { return strings.Contains(strings.ToLower(line), query) }, nil
+}
+
+// searchLogFile returns up to limit lines of the log file at path matching match, with contextLines lines around them.
+func searchLogFile(path string, match func(string) bool, contextLines, limit int) ([]logMatch, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer f.Close()
+
+	var matches []logMatch
+	var before []string
+	// matches still collecting the lines that follow them
+	var pending []int
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), client.MaxLogLineSize)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+
+		open := pending[:0]
+		for _, i := range pending {
+			matches[i].After = append(matches[i].After, line)
+			if len(matches[i].After) < contextLines {
+				open = append(open, i)
+			}
+		}
+		pending = open
+
+		if len(matches) < limit && match(line) {
+			matches = append(matches, logMatch{
+				Line:   lineNumber,
+				Text:   line,
+				Before: append([]string(nil), before...),
+			})
+			if contextLines > 0 {
+				pending = append(pending, len(matches)-1)
+			}
+		} else if len(matches) >= limit && len(pending) == 0 {
+			break
+		}
+
+		if contextLines > 0 {
+			before = append(before, line)
+			if len(before) > contextLines {
+				before = before[1:]
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log file: %w", err)
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,228 @@
+package harness
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// zipEntry is an entry of a test log archive
+type zipEntry struct {
+	name    string
+	content string
+	symlink bool
+}
+
+// writeLogArchive writes a zip archive with the given entries and returns its path.
+func writeLogArchive(t *testing.T, entries []zipEntry) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), logArchiveName)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		if entry.symlink {
+			header.SetMode(os.ModeSymlink | 0777)
+		}
+		fw, err := w.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(entry.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// logLines returns the JSON log lines of the given outputs, as the log service stores them.
+func logLines(outs ...string) string {
+	var b strings.Builder
+	for _, out := range outs {
+		fmt.Fprintf(&b, "{\"level\":\"info\",\"out\":%q}\n", out)
+	}
+	return b.String()
+}
+
+func TestExtractLogArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []zipEntry
+		// the extracted files by path, relative to the extraction directory
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "logs as plain text",
+			entries: []zipEntry{
+				{name: "acc/pipeline/build/exec1/stage1/step1", content: logLines("compiling\n", "done\r\n")},
+				{name: "acc/pipeline/build/exec1/stage1/step2", content: "not json\n\n"},
+			},
+			want: map[string]string{
+				"acc/pipeline/build/exec1/stage1/step1": "compiling\ndone\n",
+				"acc/pipeline/build/exec1/stage1/step2": "not json\n",
+			},
+		},
+		{
+			name: "unportable characters and leading slashes",
+			entries: []zipEntry{
+				{name: "/acc:pipeline/exec 1/step?1", content: logLines("ok")},
+			},
+			want: map[string]string{
+				"acc_pipeline/exec_1/step_1": "ok\n",
+			},
+		},
+		{
+			name: "directories and symlinks are skipped",
+			entries: []zipEntry{
+				{name: "acc/dir/"},
+				{name: "acc/link", content: "/etc/passwd", symlink: true},
+				{name: "acc/step", content: logLines("ok")},
+			},
+			want: map[string]string{
+				"acc/step": "ok\n",
+			},
+		},
+		{
+			name: "parent directory",
+			entries: []zipEntry{
+				{name: "../../evil", content: logLines("pwned")},
+			},
+			wantErr: "points outside of the extraction directory",
+		},
+		{
+			name: "parent directory inside the path",
+			entries: []zipEntry{
+				{name: "acc/step", content: logLines("ok")},
+				{name: "acc/../../evil", content: logLines("pwned")},
+			},
+			wantErr: "points outside of the extraction directory",
+		},
+		{
+			name: "too many entries",
+			entries: func() []zipEntry {
+				entries := make([]zipEntry, maxLogArchiveEntries+1)
+				for i := range entries {
+					entries[i] = zipEntry{name: fmt.Sprintf("acc/step%d", i)}
+				}
+				return entries
+			}(),
+			wantErr: "more than the limit",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			dest := filepath.Join(root, "logs")
+			entries, err := extractLogArchive(writeLogArchive(t, tt.entries), dest)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("extractLogArchive error = %v, want %q", err, tt.wantErr)
+				}
+				if _, err := os.Stat(filepath.Join(root, "evil")); err == nil {
+					t.Error("an entry was written outside of the extraction directory")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractLogArchive: %v", err)
+			}
+
+			if len(entries) != len(tt.want) {
+				t.Errorf("extracted %d entries, want %d", len(entries), len(tt.want))
+			}
+			for _, entry := range entries {
+				want, ok := tt.want[filepath.ToSlash(entry.Path)]
+				if !ok {
+					t.Errorf("unexpected entry %s", entry.Path)
+					continue
+				}
+				data, err := os.ReadFile(filepath.Join(dest, entry.Path))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != want {
+					t.Errorf("entry %s has %q, want %q", entry.Path, data, want)
+				}
+				if entry.Lines != strings.Count(want, "\n") {
+					t.Errorf("entry %s has %d lines, want %d", entry.Path, entry.Lines, strings.Count(want, "\n"))
+				}
+
+				info, err := os.Stat(filepath.Join(dest, entry.Path))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if perm := info.Mode().Perm(); perm != 0600 {
+					t.Errorf("entry %s has mode %v, want 0600", entry.Path, perm)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractLogEntryLimit(t *testing.T) {
+	content := logLines(strings.Repeat("x", 100), strings.Repeat("y", 100), strings.Repeat("z", 100))
+
+	tests := []struct {
+		name    string
+		limit   int64
+		wantErr bool
+	}{
+		{name: "under the limit", limit: int64(len(content)) + 1},
+		{name: "at the limit", limit: int64(len(content))},
+		{name: "over the limit", limit: int64(len(content)) - 1, wantErr: true},
+		{name: "far over the limit", limit: 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := zip.OpenReader(writeLogArchive(t, []zipEntry{{name: "acc/step", content: content}}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			entry, err := extractLogEntry(r.File[0], filepath.Join(t.TempDir(), "step"), tt.limit)
+			if tt.wantErr {
+				if !errors.Is(err, errLogArchiveTooLarge) {
+					t.Errorf("extractLogEntry error = %v, want %v", err, errLogArchiveTooLarge)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractLogEntry: %v", err)
+			}
+			if entry.Bytes != int64(len(content)) || entry.Lines != 3 {
+				t.Errorf("extractLogEntry = %d bytes, %d lines, want %d bytes, 3 lines", entry.Bytes, entry.Lines, len(content))
+			}
+		})
+	}
+}
+
+func TestSanitizeLogEntryName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "acc/pipeline/exec/stage/step", want: "acc/pipeline/exec/stage/step"},
+		{name: "/acc:pipeline", want: "acc_pipeline"},
+		{name: `..\..\evil`, want: ".._.._evil"},
+		{name: "../evil", want: "../evil"},
+	}
+	for _, tt := range tests {
+		if got := filepath.ToSlash(sanitizeLogEntryName(tt.name)); got != tt.want {
+			t.Errorf("sanitizeLogEntryName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
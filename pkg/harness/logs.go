@@ -2,18 +2,37 @@ package harness
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/harness/harness-mcp/client"
+	"github.com/harness/harness-mcp/client/dto"
 	"github.com/harness/harness-mcp/cmd/harness-mcp-server/config"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+const (
+	defaultLogTailLines = 200
+	maxLogTailLines     = 2000
+	defaultLogByteLimit = 64 * 1024
+	maxLogByteLimit     = 1024 * 1024
+
+	defaultTailSeconds = 30
+	maxTailSeconds     = 120
+	tailPollInterval   = 5 * time.Second
+
+	defaultLogContextLines = 2
+	maxLogContextLines     = 10
+	defaultLogMatches      = 50
+	maxLogMatches          = 500
+)
+
 // DownloadExecutionLogsTool creates a tool for downloading logs for a pipeline execution
 // TODO: to make this easy to use, we ask to pass in an output path and do the complete download of the logs.
 // This is less work for the user, but we may want to only return the download instruction instead in the future.
@@ -41,11 +60,6 @@ func DownloadExecutionLogsTool(config *config.Config, client *client.Client) (to
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
-			logDownloadURL, err := client.Logs.DownloadLogs(ctx, scope, planExecutionID)
-			if err != nil {
-				return nil, fmt.Errorf("failed to fetch log download URL: %w", err)
-			}
-
 			logsDirectory, err := requiredParam[string](request, "logs_directory")
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
@@ -69,42 +83,501 @@ func DownloadExecutionLogsTool(config *config.Config, client *client.Client) (to
 				return mcp.NewToolResultError(fmt.Sprintf("failed to create logs folder: %v", err)), nil
 			}
 
-			// Get the download URL
-			logDownloadURL, err = client.Logs.DownloadLogs(ctx, scope, planExecutionID)
+			// Download the logs into outputPath
+			logsZipPath := filepath.Join(logsFolderPath, logArchiveName)
+			bytesWritten, err := downloadLogArchive(ctx, client, scope, planExecutionID, logsZipPath)
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("failed to fetch log download URL: %v", err)), nil
+				return apiErrorResult(err, "download logs", scope), nil
 			}
 
-			// Download the logs into outputPath
-			resp, err := http.Get(logDownloadURL)
+			// Extract the logs next to the archive so they can be read without unzipping them
+			extractedPath := filepath.Join(logsFolderPath, logFilesDir)
+			entries, err := extractLogArchive(logsZipPath, extractedPath)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to extract logs: %v", err)), nil
+			}
+
+			// Success message with download details
+			instruction := fmt.Sprintf("Successfully downloaded logs to %s (%d bytes) and extracted %d step logs as plain text to %s. Use search_execution_logs to search them.", logsZipPath, bytesWritten, len(entries), extractedPath)
+
+			return mcp.NewToolResultText(instruction), nil
+		}
+}
+
+// SearchExecutionLogsTool creates a tool for searching the logs of a pipeline execution
+func SearchExecutionLogsTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("search_execution_logs",
+			mcp.WithDescription("Search the logs of all steps of a pipeline execution in Harness for a keyword or regular expression. Returns the matching lines with their line numbers and surrounding lines, grouped by stage and step. The logs are downloaded and indexed on the first search of an execution."),
+			WithOutputSchema[logSearchResult](),
+			WithResponseShaping(),
+			mcp.WithString("plan_execution_id",
+				mcp.Required(),
+				mcp.Description("The ID of the plan execution"),
+			),
+			mcp.WithString("query",
+				mcp.Required(),
+				mcp.Description("The keyword or regular expression to search for"),
+			),
+			mcp.WithBoolean("regex",
+				mcp.Description("Optional flag to treat the query as a regular expression (RE2 syntax)"),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithBoolean("case_sensitive",
+				mcp.Description("Optional flag to match case"),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithNumber("context_lines",
+				mcp.DefaultNumber(defaultLogContextLines),
+				mcp.Max(maxLogContextLines),
+				mcp.Description("Number of lines to return before and after each match"),
+			),
+			mcp.WithString("stage_id",
+				mcp.Description("Optional stage identifier to only search the logs of that stage"),
+			),
+			mcp.WithString("step_id",
+				mcp.Description("Optional step identifier to only search the logs of that step"),
+			),
+			mcp.WithNumber("max_matches",
+				mcp.DefaultNumber(defaultLogMatches),
+				mcp.Max(maxLogMatches),
+				mcp.Description("Maximum number of matches to return"),
+			),
+			mcp.WithBoolean("refresh",
+				mcp.Description("Optional flag to download the logs again instead of using the logs indexed by a previous search"),
+				mcp.DefaultBool(false),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			planExecutionID, err := requiredParam[string](request, "plan_execution_id")
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("failed to download logs: %v", err)), nil
+				return mcp.NewToolResultError(err.Error()), nil
 			}
-			defer resp.Body.Close()
 
-			if resp.StatusCode != http.StatusOK {
-				return mcp.NewToolResultError(fmt.Sprintf("failed to download logs: unexpected status code %d", resp.StatusCode)), nil
+			query, err := requiredParam[string](request, "query")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			isRegex, err := OptionalParam[bool](request, "regex")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
 
-			// Create the logs.zip file path
-			logsZipPath := filepath.Join(logsFolderPath, "logs.zip")
+			caseSensitive, err := OptionalParam[bool](request, "case_sensitive")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 
-			// Create the output file
-			outputFile, err := os.Create(logsZipPath)
+			contextLines, ok, err := OptionalParamOK[float64](request, "context_lines")
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("failed to create output file: %v", err)), nil
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if !ok {
+				contextLines = defaultLogContextLines
 			}
-			defer outputFile.Close()
 
-			// Copy the response body to the output file
-			bytesWritten, err := io.Copy(outputFile, resp.Body)
+			stageID, err := OptionalParam[string](request, "stage_id")
 			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("failed to write logs to file: %v", err)), nil
+				return mcp.NewToolResultError(err.Error()), nil
 			}
 
-			// Success message with download details
-			instruction := fmt.Sprintf("Successfully downloaded logs to %s (%d bytes)! You can unzip and analyze these logs.", logsZipPath, bytesWritten)
+			stepID, err := OptionalParam[string](request, "step_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 
-			return mcp.NewToolResultText(instruction), nil
+			maxMatches, err := OptionalIntParamWithDefault(request, "max_matches", defaultLogMatches)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			maxMatches = min(max(maxMatches, 1), maxLogMatches)
+
+			refresh, err := OptionalParam[bool](request, "refresh")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			match, err := newLogMatcher(query, isRegex, caseSensitive)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			dir, release, err := executionLogs.acquire(scope, planExecutionID)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			defer release()
+
+			index, err := prepareExecutionLogs(ctx, client, scope, planExecutionID, dir, refresh)
+			if err != nil {
+				return apiErrorResult(err, "prepare execution logs", scope), nil
+			}
+
+			result := logSearchResult{
+				PlanExecutionID: planExecutionID,
+				Query:           query,
+			}
+			for _, entry := range index.Entries {
+				if (stageID != "" && entry.StageID != stageID) || (stepID != "" && entry.StepID != stepID) {
+					continue
+				}
+				if result.TotalMatches >= maxMatches {
+					break
+				}
+				result.SearchedLogs++
+
+				matches, err := searchLogFile(filepath.Join(dir, logFilesDir, entry.Path), match,
+					min(max(int(contextLines), 0), maxLogContextLines), maxMatches-result.TotalMatches)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				if len(matches) == 0 {
+					continue
+				}
+				result.TotalMatches += len(matches)
+				result.Steps = append(result.Steps, stepLogMatches{
+					StageID:  entry.StageID,
+					StepID:   entry.StepID,
+					StepName: entry.StepName,
+					Matches:  matches,
+				})
+			}
+
+			// the search stops once enough matches are found, there may be more
+			result.Truncated = result.TotalMatches >= maxMatches
+
+			return structuredResult(result, "log search result")
+		}
+}
+
+// GetStepLogsTool creates a tool for getting the log of a single step of a pipeline execution
+func GetStepLogsTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("get_step_logs",
+			mcp.WithDescription("Get the log of a single step of a pipeline execution in Harness, without downloading the logs of the whole execution. Returns the last lines of the log by default, or a byte range of it. Without a step_id, the log of the first failed step is returned."),
+			WithTextShaping(),
+			mcp.WithString("plan_execution_id",
+				mcp.Required(),
+				mcp.Description("The ID of the plan execution"),
+			),
+			mcp.WithString("step_id",
+				mcp.Description("Optional identifier or node execution ID of the step, defaults to the first failed step"),
+			),
+			mcp.WithString("stage_id",
+				mcp.Description("Optional identifier of the stage of the step, needed when several stages have a step with the same identifier"),
+			),
+			mcp.WithNumber("tail_lines",
+				mcp.DefaultNumber(defaultLogTailLines),
+				mcp.Max(maxLogTailLines),
+				mcp.Description("Number of lines to return from the end of the log"),
+			),
+			mcp.WithNumber("byte_offset",
+				mcp.Min(0),
+				mcp.Description("Optional offset in bytes to read the log from, instead of returning its last lines"),
+			),
+			mcp.WithNumber("byte_limit",
+				mcp.Max(maxLogByteLimit),
+				mcp.Description(fmt.Sprintf("Optional number of bytes to read from byte_offset, defaults to %d", defaultLogByteLimit)),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			planExecutionID, err := requiredParam[string](request, "plan_execution_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			stepID, err := OptionalParam[string](request, "step_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			stageID, err := OptionalParam[string](request, "stage_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			tailLines, err := OptionalIntParamWithDefault(request, "tail_lines", defaultLogTailLines)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			tailLines = min(max(tailLines, 1), maxLogTailLines)
+
+			byteOffset, hasByteOffset, err := OptionalParamOK[float64](request, "byte_offset")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			byteLimit, err := OptionalIntParam(request, "byte_limit")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if byteLimit > 0 {
+				hasByteOffset = true
+			}
+			if byteLimit <= 0 {
+				byteLimit = defaultLogByteLimit
+			}
+			byteLimit = min(byteLimit, maxLogByteLimit)
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			data, err := client.Pipelines.GetExecutionGraph(ctx, scope, planExecutionID)
+			if err != nil {
+				return apiErrorResult(err, "get execution graph", scope), nil
+			}
+
+			stage, step, err := findStep(buildExecutionTree(data.Data), stageID, stepID, isFailedStatus, "failed")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if len(step.LogKeys) == 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("step %s in stage %s has no logs", step.Identifier, stage.Identifier)), nil
+			}
+
+			var collector logCollector
+			if hasByteOffset {
+				collector = &logRange{offset: int(max(byteOffset, 0)), limit: byteLimit}
+			} else {
+				collector = &logTail{size: tailLines}
+			}
+
+			for _, key := range step.LogKeys {
+				if err := client.Logs.StreamLog(ctx, scope, key, collector.add); err != nil {
+					return apiErrorResult(err, "get step logs", scope), nil
+				}
+			}
+
+			header := fmt.Sprintf("Log of step %s (%s) in stage %s, status %s: %s", step.Identifier, step.Name, stage.Identifier, step.Status, collector.describe())
+			return mcp.NewToolResultText(header + "\n\n" + collector.text()), nil
 		}
 }
+
+// TailExecutionLogsTool creates a tool for following the live log of a running step
+func TailExecutionLogsTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("tail_execution_logs",
+			mcp.WithDescription("Follow the live log of a running step of a pipeline execution in Harness, for a bounded duration or until the step finishes, and return the collected lines. Sends progress notifications while waiting. Without a step_id, the first running step is followed. Use get_step_logs for steps that already finished."),
+			mcp.WithString("plan_execution_id",
+				mcp.Required(),
+				mcp.Description("The ID of the plan execution"),
+			),
+			mcp.WithString("step_id",
+				mcp.Description("Optional identifier or node execution ID of the step, defaults to the first running step"),
+			),
+			mcp.WithString("stage_id",
+				mcp.Description("Optional identifier of the stage of the step, needed when several stages have a step with the same identifier"),
+			),
+			mcp.WithNumber("duration_seconds",
+				mcp.DefaultNumber(defaultTailSeconds),
+				mcp.Max(maxTailSeconds),
+				mcp.Description("Maximum number of seconds to follow the log for"),
+			),
+			mcp.WithNumber("max_lines",
+				mcp.DefaultNumber(defaultLogTailLines),
+				mcp.Max(maxLogTailLines),
+				mcp.Description("Maximum number of lines to return, the most recent lines are kept"),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			planExecutionID, err := requiredParam[string](request, "plan_execution_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			stepID, err := OptionalParam[string](request, "step_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			stageID, err := OptionalParam[string](request, "stage_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			durationSeconds, err := OptionalIntParamWithDefault(request, "duration_seconds", defaultTailSeconds)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			durationSeconds = min(max(durationSeconds, 1), maxTailSeconds)
+
+			maxLines, err := OptionalIntParamWithDefault(request, "max_lines", defaultLogTailLines)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			maxLines = min(max(maxLines, 1), maxLogTailLines)
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			data, err := client.Pipelines.GetExecutionGraph(ctx, scope, planExecutionID)
+			if err != nil {
+				return apiErrorResult(err, "get execution graph", scope), nil
+			}
+
+			stage, step, err := findStep(buildExecutionTree(data.Data), stageID, stepID, isRunningStatus, "running")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if isTerminalStatus(step.Status) {
+				return mcp.NewToolResultError(fmt.Sprintf("step %s in stage %s already finished with status %s, use get_step_logs to read its log", step.Identifier, stage.Identifier, step.Status)), nil
+			}
+			if len(step.LogKeys) == 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("step %s in stage %s has no log yet, its status is %s", step.Identifier, stage.Identifier, step.Status)), nil
+			}
+
+			start := time.Now()
+			tailCtx, cancel := context.WithTimeout(ctx, time.Duration(durationSeconds)*time.Second)
+			defer cancel()
+
+			// watch the step while its log is streamed, to report progress and stop once it finishes
+			var received atomic.Int64
+			status := step.Status
+			watched := make(chan struct{})
+			go func() {
+				defer close(watched)
+				ticker := time.NewTicker(tailPollInterval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-tailCtx.Done():
+						return
+					case <-ticker.C:
+					}
+
+					sendProgress(ctx, request, time.Since(start).Seconds(),
+						fmt.Sprintf("received %d lines from step %s", received.Load(), step.Identifier))
+
+					data, err := client.Pipelines.GetExecutionGraph(tailCtx, scope, planExecutionID)
+					if err != nil {
+						continue
+					}
+					_, current, err := findStep(buildExecutionTree(data.Data), stage.Identifier, step.NodeExecutionID, isRunningStatus, "running")
+					if err == nil && isTerminalStatus(current.Status) {
+						status = current.Status
+						cancel()
+						return
+					}
+				}
+			}()
+
+			collector := &logTail{size: maxLines}
+			err = client.Logs.TailLog(tailCtx, scope, step.LogKeys[len(step.LogKeys)-1], func(line dto.LogLine) bool {
+				received.Add(1)
+				return collector.add(line)
+			})
+			cancel()
+			<-watched
+			if err != nil && (ctx.Err() != nil || !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled)) {
+				return apiErrorResult(err, "tail step logs", scope), nil
+			}
+
+			var state string
+			switch {
+			case isTerminalStatus(status):
+				state = "finished with status " + status
+			case err == nil:
+				state = "log stream closed, status " + status
+			default:
+				state = fmt.Sprintf("still %s after %ds", status, durationSeconds)
+			}
+			header := fmt.Sprintf("Followed step %s (%s) in stage %s for %s, %s: %s", step.Identifier, step.Name, stage.Identifier,
+				time.Since(start).Round(time.Second), state, collector.describe())
+			return mcp.NewToolResultText(header + "\n\n" + collector.text()), nil
+		}
+}
+
+// logCollector keeps the part of a log that is returned to the client.
+type logCollector interface {
+	// add adds the next line of the log, it returns false once no more lines are needed
+	add(line dto.LogLine) bool
+	// describe describes which part of the log was kept
+	describe() string
+	// text returns the kept part of the log
+	text() string
+}
+
+// logTail keeps the last lines of a log.
+type logTail struct {
+	size  int
+	lines []string
+	total int
+}
+
+func (t *logTail) add(line dto.LogLine) bool {
+	t.total++
+	t.lines = append(t.lines, strings.TrimRight(line.Out, "\r\n"))
+	if len(t.lines) > 2*t.size {
+		t.lines = append(t.lines[:0], t.lines[len(t.lines)-t.size:]...)
+	}
+	return true
+}
+
+func (t *logTail) describe() string {
+	if len(t.lines) > t.size {
+		t.lines = t.lines[len(t.lines)-t.size:]
+	}
+	if len(t.lines) == t.total {
+		return fmt.Sprintf("all %d lines", t.total)
+	}
+	return fmt.Sprintf("last %d of %d lines", len(t.lines), t.total)
+}
+
+func (t *logTail) text() string {
+	return strings.Join(t.lines, "\n")
+}
+
+// logRange keeps a byte range of a log.
+type logRange struct {
+	offset int
+	limit  int
+	pos    int
+	buf    strings.Builder
+	more   bool
+}
+
+func (r *logRange) add(line dto.LogLine) bool {
+	out := strings.TrimRight(line.Out, "\r\n") + "\n"
+	end := r.offset + r.limit
+	if r.pos >= end {
+		r.more = true
+		return false
+	}
+
+	start, stop := r.pos, r.pos+len(out)
+	r.pos = stop
+	if stop <= r.offset {
+		return true
+	}
+	r.buf.WriteString(out[max(r.offset-start, 0):min(end-start, len(out))])
+	if stop > end {
+		r.more = true
+		return false
+	}
+	return true
+}
+
+func (r *logRange) describe() string {
+	if r.buf.Len() == 0 {
+		return fmt.Sprintf("no output at byte offset %d, the log has %d bytes", r.offset, r.pos)
+	}
+	description := fmt.Sprintf("bytes %d-%d", r.offset, r.offset+r.buf.Len()-1)
+	if r.more {
+		return description + ", more output follows"
+	}
+	return description + ", end of log"
+}
+
+func (r *logRange) text() string {
+	return r.buf.String()
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"strings"
 
 	"github.com/harness/harness-mcp/client"
 	"github.com/harness/harness-mcp/client/dto"
@@ -15,6 +17,8 @@ import (
 func GetPipelineTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_pipeline",
 			mcp.WithDescription("Get details of a specific pipeline in a Harness repository."),
+			WithOutputSchema[dto.PipelineData](),
+			WithResponseShaping(),
 			mcp.WithString("pipeline_id",
 				mcp.Required(),
 				mcp.Description("The ID of the pipeline"),
@@ -33,21 +37,18 @@ func GetPipelineTool(config *config.Config, client *client.Client) (tool mcp.Too
 
 			data, err := client.Pipelines.Get(ctx, scope, pipelineID)
 			if err != nil {
-				return nil, fmt.Errorf("failed to get pipeline: %w", err)
+				return apiErrorResult(err, "get pipeline", scope), nil
 			}
 
-			r, err := json.Marshal(data.Data)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal pipeline: %w", err)
-			}
-
-			return mcp.NewToolResultText(string(r)), nil
+			return structuredResult(data.Data, "pipeline")
 		}
 }
 
 func ListPipelinesTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("list_pipelines",
 			mcp.WithDescription("List pipelines in a Harness repository."),
+			WithOutputSchema[dto.ListOutput[dto.PipelineListItem]](),
+			WithResponseShaping(),
 			mcp.WithString("search_term",
 				mcp.Description("Optional search term to filter pipelines"),
 			),
@@ -80,15 +81,10 @@ func ListPipelinesTool(config *config.Config, client *client.Client) (tool mcp.T
 
 			data, err := client.Pipelines.List(ctx, scope, opts)
 			if err != nil {
-				return nil, fmt.Errorf("failed to list pipelines: %w", err)
-			}
-
-			r, err := json.Marshal(data)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal pipeline list: %w", err)
+				return apiErrorResult(err, "list pipelines", scope), nil
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return structuredResult(data, "pipeline list")
 		}
 }
 
@@ -123,7 +119,7 @@ func FetchExecutionURLTool(config *config.Config, client *client.Client) (tool m
 
 			url, err := client.Pipelines.FetchExecutionURL(ctx, scope, pipelineID, planExecutionID)
 			if err != nil {
-				return nil, fmt.Errorf("failed to fetch execution URL: %w", err)
+				return apiErrorResult(err, "fetch execution URL", scope), nil
 			}
 
 			return mcp.NewToolResultText(url), nil
@@ -133,6 +129,8 @@ func FetchExecutionURLTool(config *config.Config, client *client.Client) (tool m
 func GetExecutionTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_execution",
 			mcp.WithDescription("Get details of a specific pipeline execution in Harness."),
+			WithOutputSchema[dto.PipelineExecution](),
+			WithResponseShaping(),
 			mcp.WithString("plan_execution_id",
 				mcp.Required(),
 				mcp.Description("The ID of the plan execution"),
@@ -152,21 +150,18 @@ func GetExecutionTool(config *config.Config, client *client.Client) (tool mcp.To
 
 			data, err := client.Pipelines.GetExecution(ctx, scope, planExecutionID)
 			if err != nil {
-				return nil, fmt.Errorf("failed to get execution details: %w", err)
+				return apiErrorResult(err, "get execution details", scope), nil
 			}
 
-			r, err := json.Marshal(data.Data)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal execution details: %w", err)
-			}
-
-			return mcp.NewToolResultText(string(r)), nil
+			return structuredResult(data.Data, "execution details")
 		}
 }
 
 func ListExecutionsTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("list_executions",
 			mcp.WithDescription("List pipeline executions in a Harness repository."),
+			WithOutputSchema[dto.ListOutput[dto.PipelineExecution]](),
+			WithResponseShaping(),
 			mcp.WithString("search_term",
 				mcp.Description("Optional search term to filter executions"),
 			),
@@ -235,14 +230,372 @@ func ListExecutionsTool(config *config.Config, client *client.Client) (tool mcp.
 
 			data, err := client.Pipelines.ListExecutions(ctx, scope, opts)
 			if err != nil {
-				return nil, fmt.Errorf("failed to list pipeline executions: %w", err)
+				return apiErrorResult(err, "list pipeline executions", scope), nil
+			}
+
+			return structuredResult(data, "pipeline executions list")
+		}
+}
+
+// runPipelineResult is returned by the run_pipeline tool
+type runPipelineResult struct {
+	PlanExecutionID string `json:"planExecutionId"`
+	Status          string `json:"status,omitempty"`
+	ExecutionURL    string `json:"executionUrl,omitempty"`
+}
+
+// RunPipelineTool creates a tool for running a pipeline
+func RunPipelineTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("run_pipeline",
+			mcp.WithDescription("Run a pipeline in Harness. Returns the plan execution ID and the URL of the new execution."),
+			WithOutputSchema[runPipelineResult](),
+			WithResponseShaping(),
+			mcp.WithString("pipeline_id",
+				mcp.Required(),
+				mcp.Description("The ID of the pipeline"),
+			),
+			mcp.WithString("runtime_input_yaml",
+				mcp.Description("Optional runtime input YAML for the pipeline. When input sets are given, it is merged on top of them"),
+			),
+			mcp.WithString("input_set_ids",
+				mcp.Description("Optional comma-separated list of input set IDs to run the pipeline with"),
+			),
+			mcp.WithString("branch",
+				mcp.Description("Optional git branch to fetch the pipeline from, for pipelines stored in git"),
+			),
+			mcp.WithString("repo_identifier",
+				mcp.Description("Optional git repository to fetch the pipeline from, for pipelines stored in git"),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			pipelineID, err := requiredParam[string](request, "pipeline_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			runtimeInputYAML, err := OptionalParam[string](request, "runtime_input_yaml")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			inputSetIDs, err := OptionalParam[string](request, "input_set_ids")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			branch, err := OptionalParam[string](request, "branch")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			repoIdentifier, err := OptionalParam[string](request, "repo_identifier")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			opts := &dto.PipelineRunOptions{
+				RuntimeInputYAML:   runtimeInputYAML,
+				InputSetReferences: parseCommaSeparatedList(inputSetIDs),
+				Branch:             branch,
+				RepoIdentifier:     repoIdentifier,
+			}
+
+			data, err := client.Pipelines.Run(ctx, scope, pipelineID, opts)
+			if err != nil {
+				return apiErrorResult(err, "run pipeline", scope), nil
+			}
+
+			if data.Data.PlanExecution.UUID == "" {
+				// the run may still have started, so it must not be repeated blindly
+				response, _ := json.Marshal(data)
+				return mcp.NewToolResultError(fmt.Sprintf("Harness did not return the ID of the new execution, check the executions of pipeline %s before running it again. Response: %s", pipelineID, response)), nil
+			}
+
+			result := runPipelineResult{
+				PlanExecutionID: data.Data.PlanExecution.UUID,
+				Status:          data.Data.PlanExecution.Status,
+			}
+
+			// The execution has already started at this point, so failing to build its URL is not fatal
+			url, err := client.Pipelines.FetchExecutionURL(ctx, scope, pipelineID, result.PlanExecutionID)
+			if err != nil {
+				slog.Warn("Failed to fetch execution URL", "plan_execution_id", result.PlanExecutionID, "error", err)
+			}
+			result.ExecutionURL = url
+
+			return structuredResult(result, "pipeline run")
+		}
+}
+
+// AbortExecutionTool creates a tool for aborting a running pipeline execution
+func AbortExecutionTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return interruptExecutionTool(config, client, "abort_execution",
+		"Abort a running pipeline execution in Harness. All running stages are aborted.",
+		dto.InterruptTypeAbort, "abort execution",
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+}
+
+// PauseExecutionTool creates a tool for pausing a running pipeline execution
+func PauseExecutionTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return interruptExecutionTool(config, client, "pause_execution",
+		"Pause a running pipeline execution in Harness. It can be continued later with resume_execution.",
+		dto.InterruptTypePause, "pause execution",
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+}
+
+// ResumeExecutionTool creates a tool for resuming a paused pipeline execution
+func ResumeExecutionTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return interruptExecutionTool(config, client, "resume_execution",
+		"Resume a paused pipeline execution in Harness.",
+		dto.InterruptTypeResume, "resume execution",
+		mcp.WithDestructiveHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	)
+}
+
+// interruptExecutionTool creates a tool that sends the given interrupt to a pipeline execution,
+// opts add the annotations of the interrupt to the tool
+func interruptExecutionTool(config *config.Config, client *client.Client, name, description, interruptType, action string, opts ...mcp.ToolOption) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool(name,
+			append([]mcp.ToolOption{
+				mcp.WithDescription(description),
+				WithOutputSchema[dto.InterruptResponse](),
+				WithResponseShaping(),
+				mcp.WithString("plan_execution_id",
+					mcp.Required(),
+					mcp.Description("The ID of the plan execution"),
+				),
+				WithScope(config, true),
+			}, opts...)...,
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			planExecutionID, err := requiredParam[string](request, "plan_execution_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			data, err := client.Pipelines.Interrupt(ctx, scope, planExecutionID, interruptType)
+			if err != nil {
+				return apiErrorResult(err, action, scope), nil
+			}
+
+			return structuredResult(data.Data, "interrupt")
+		}
+}
+
+// retryInfoResult is returned by the get_execution_retry_info tool
+type retryInfoResult struct {
+	RetryStages  dto.RetryInfo    `json:"retryStages"`
+	RetryHistory dto.RetryHistory `json:"retryHistory"`
+}
+
+// GetExecutionRetryInfoTool creates a tool for listing the stages an execution can be retried from
+func GetExecutionRetryInfoTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("get_execution_retry_info",
+			mcp.WithDescription("Get the stages a pipeline execution can be retried from, grouped by parallel stage groups, along with the history of previous retries."),
+			WithOutputSchema[retryInfoResult](),
+			WithResponseShaping(),
+			mcp.WithString("pipeline_id",
+				mcp.Required(),
+				mcp.Description("The ID of the pipeline"),
+			),
+			mcp.WithString("plan_execution_id",
+				mcp.Required(),
+				mcp.Description("The ID of the plan execution"),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			pipelineID, err := requiredParam[string](request, "pipeline_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			planExecutionID, err := requiredParam[string](request, "plan_execution_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			stages, err := client.Pipelines.GetRetryStages(ctx, scope, pipelineID, planExecutionID)
+			if err != nil {
+				return apiErrorResult(err, "get retry stages", scope), nil
+			}
+
+			history, err := client.Pipelines.GetRetryHistory(ctx, scope, pipelineID, planExecutionID)
+			if err != nil {
+				return apiErrorResult(err, "get retry history", scope), nil
+			}
+
+			return structuredResult(retryInfoResult{
+				RetryStages:  stages.Data,
+				RetryHistory: history.Data,
+			}, "retry info")
+		}
+}
+
+// RetryExecutionTool creates a tool for retrying a failed pipeline execution
+func RetryExecutionTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("retry_execution",
+			mcp.WithDescription("Retry a failed pipeline execution in Harness from the failed stage, or from the given stages. Returns the plan execution ID and the URL of the new execution."),
+			WithOutputSchema[runPipelineResult](),
+			WithResponseShaping(),
+			mcp.WithString("pipeline_id",
+				mcp.Required(),
+				mcp.Description("The ID of the pipeline"),
+			),
+			mcp.WithString("plan_execution_id",
+				mcp.Required(),
+				mcp.Description("The ID of the plan execution to retry"),
+			),
+			mcp.WithString("retry_stages",
+				mcp.Description("Optional comma-separated list of stage IDs to retry from, as returned by get_execution_retry_info. Defaults to the failed stages"),
+			),
+			mcp.WithBoolean("run_all_stages",
+				mcp.Description("Optional flag to retry all the stages of a parallel group instead of only the failed ones"),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithString("runtime_input_yaml",
+				mcp.Description("Optional runtime input YAML for the retried execution"),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			pipelineID, err := requiredParam[string](request, "pipeline_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			planExecutionID, err := requiredParam[string](request, "plan_execution_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			retryStages, err := OptionalParam[string](request, "retry_stages")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			runAllStages, err := OptionalParam[bool](request, "run_all_stages")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			runtimeInputYAML, err := OptionalParam[string](request, "runtime_input_yaml")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			opts := &dto.PipelineRetryOptions{
+				RetryStages:      parseCommaSeparatedList(retryStages),
+				RunAllStages:     runAllStages,
+				RuntimeInputYAML: runtimeInputYAML,
+			}
+
+			// Default to retrying from the first group of stages that failed
+			if len(opts.RetryStages) == 0 {
+				stages, err := client.Pipelines.GetRetryStages(ctx, scope, pipelineID, planExecutionID)
+				if err != nil {
+					return apiErrorResult(err, "get retry stages", scope), nil
+				}
+				opts.RetryStages = failedRetryStages(stages.Data)
+				if len(opts.RetryStages) == 0 {
+					return mcp.NewToolResultError("no failed stages found to retry from, pass retry_stages explicitly"), nil
+				}
+			}
+
+			data, err := client.Pipelines.Retry(ctx, scope, pipelineID, planExecutionID, opts)
+			if err != nil {
+				return apiErrorResult(err, "retry execution", scope), nil
+			}
+
+			if data.Data.PlanExecution.UUID == "" {
+				// the retry may still have started, so it must not be repeated blindly
+				response, _ := json.Marshal(data)
+				return mcp.NewToolResultError(fmt.Sprintf("Harness did not return the ID of the new execution, check the executions of pipeline %s before retrying it again. Response: %s", pipelineID, response)), nil
+			}
+
+			result := runPipelineResult{
+				PlanExecutionID: data.Data.PlanExecution.UUID,
+				Status:          data.Data.PlanExecution.Status,
 			}
 
-			r, err := json.Marshal(data)
+			// The retry has already started at this point, so failing to build its URL is not fatal
+			url, err := client.Pipelines.FetchExecutionURL(ctx, scope, pipelineID, result.PlanExecutionID)
 			if err != nil {
-				return nil, fmt.Errorf("failed to marshal pipeline executions list: %w", err)
+				slog.Warn("Failed to fetch execution URL", "plan_execution_id", result.PlanExecutionID, "error", err)
 			}
+			result.ExecutionURL = url
+
+			return structuredResult(result, "pipeline retry")
+		}
+}
 
-			return mcp.NewToolResultText(string(r)), nil
+// failedRetryStages returns the failed stages of the first group that has any
+func failedRetryStages(info dto.RetryInfo) []string {
+	for _, group := range info.Groups {
+		var failed []string
+		for _, stage := range group.Info {
+			if isFailedStatus(stage.Status) {
+				failed = append(failed, stage.Identifier)
+			}
+		}
+		if len(failed) > 0 {
+			return failed
 		}
+	}
+	return nil
+}
+
+// isFailedStatus checks if an execution, stage or step status is a terminal failure
+func isFailedStatus(status string) bool {
+	switch strings.ToLower(status) {
+	case "failed", "errored", "aborted", "expired", "approvalrejected", "abortedbyfreeze":
+		return true
+	default:
+		return false
+	}
+}
+
+// isTerminalStatus checks if an execution, stage or step status is final
+func isTerminalStatus(status string) bool {
+	switch strings.ToLower(status) {
+	case "success", "ignorefailed", "skipped", "suspended":
+		return true
+	default:
+		return isFailedStatus(status)
+	}
+}
+
+// isRunningStatus checks if an execution, stage or step is in progress
+func isRunningStatus(status string) bool {
+	switch strings.ToLower(status) {
+	case "running", "asyncwaiting", "taskwaiting", "timedwaiting", "waitsteprunning":
+		return true
+	default:
+		return false
+	}
 }
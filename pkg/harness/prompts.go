@@ -0,0 +1,417 @@
+package harness
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/harness/harness-mcp/client"
+	"github.com/harness/harness-mcp/client/dto"
+	"github.com/harness/harness-mcp/cmd/harness-mcp-server/config"
+	"github.com/harness/harness-mcp/pkg/redact"
+	"github.com/harness/harness-mcp/pkg/toolsets"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// number of recent executions included when explaining a pipeline
+const promptRecentExecutions = 5
+
+// RegisterPrompts registers the prompts of the enabled toolsets with the server. The prompts fetch the data
+// they are about up front and embed it, so the model can start from it instead of calling tools first.
+// If redactor is not nil, secrets are redacted from the embedded data.
+func RegisterPrompts(s *server.MCPServer, tsg *toolsets.ToolsetGroup, client *client.Client, config *config.Config, redactor *redact.Redactor) {
+	for _, name := range toolsetNames(tsg) {
+		if tsg.IsEnabled(name) {
+			RegisterToolsetPrompts(s, name, client, config, redactor)
+		}
+	}
+}
+
+// RegisterToolsetPrompts registers the prompts of a toolset, e.g. when it is enabled at runtime.
+func RegisterToolsetPrompts(s *server.MCPServer, name string, client *client.Client, config *config.Config, redactor *redact.Redactor) {
+	add := func(prompt mcp.Prompt, handler server.PromptHandlerFunc) {
+		if redactor != nil {
+			handler = redactPrompt(redactor, handler)
+		}
+		s.AddPrompt(prompt, handler)
+	}
+
+	switch name {
+	case "pipelines":
+		add(TriageFailedExecutionPrompt(config, client))
+		add(ExplainPipelinePrompt(config, client))
+	case "pullrequests":
+		add(ReviewPullRequestPrompt(config, client))
+		add(WhyIsPullRequestBlockedPrompt(config, client))
+	}
+}
+
+// TriageFailedExecutionPrompt creates a prompt for finding out why an execution failed and how to fix it
+func TriageFailedExecutionPrompt(config *config.Config, client *client.Client) (prompt mcp.Prompt, handler server.PromptHandlerFunc) {
+	return mcp.NewPrompt("triage_failed_execution",
+			mcp.WithPromptDescription("Triage a failed pipeline execution: find the root cause in its failure messages and step logs and suggest a fix"),
+			mcp.WithArgument("plan_execution_id",
+				mcp.ArgumentDescription("The ID of the plan execution"),
+				mcp.RequiredArgument(),
+			),
+			withPromptScope(),
+		),
+		func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			planExecutionID, err := requiredPromptArgument(request, "plan_execution_id")
+			if err != nil {
+				return nil, err
+			}
+			scope, err := promptScope(config, request)
+			if err != nil {
+				return nil, err
+			}
+
+			data, err := client.Pipelines.GetExecutionGraph(ctx, scope, planExecutionID)
+			if err != nil {
+				return nil, errors.New(describeAPIError(err, "get execution graph", scope))
+			}
+			summary := summarizeExecutionFailure(ctx, client, scope, data.Data, "", "", defaultFailureSnippets)
+
+			uri := scopedResourceURI(scope, "executions", planExecutionID)
+			tree, err := jsonPromptResource(uri, buildExecutionTree(data.Data))
+			if err != nil {
+				return nil, err
+			}
+			failure, err := jsonPromptText("Failure analysis", summary)
+			if err != nil {
+				return nil, err
+			}
+
+			instructions := fmt.Sprintf(`Triage the failed Harness pipeline execution %s of pipeline %s (status %s).
+
+The execution with its stages and steps and an analysis of its failure messages and of the logs of its failed steps are attached.
+1. Identify the root cause. Distinguish it from errors that are only consequences of it, and say which stage and step it occurred in.
+2. Classify it: a problem in the code under build or test, in the pipeline configuration, in the infrastructure, or a flaky failure.
+3. Suggest a concrete fix, and whether retrying the execution is likely to help.
+If the attached data is not enough, use get_step_logs or search_execution_logs to read more of the logs.`,
+				planExecutionID, summary.PipelineIdentifier, summary.Status)
+
+			return mcp.NewGetPromptResult(
+				fmt.Sprintf("Triage failed execution %s", planExecutionID),
+				[]mcp.PromptMessage{
+					mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(instructions)),
+					mcp.NewPromptMessage(mcp.RoleUser, tree),
+					mcp.NewPromptMessage(mcp.RoleUser, failure),
+				},
+			), nil
+		}
+}
+
+// ExplainPipelinePrompt creates a prompt for explaining what a pipeline does
+func ExplainPipelinePrompt(config *config.Config, client *client.Client) (prompt mcp.Prompt, handler server.PromptHandlerFunc) {
+	return mcp.NewPrompt("explain_pipeline",
+			mcp.WithPromptDescription("Explain what a pipeline does, stage by stage, based on its YAML and its recent executions"),
+			mcp.WithArgument("pipeline_id",
+				mcp.ArgumentDescription("The ID of the pipeline"),
+				mcp.RequiredArgument(),
+			),
+			withPromptScope(),
+		),
+		func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			pipelineID, err := requiredPromptArgument(request, "pipeline_id")
+			if err != nil {
+				return nil, err
+			}
+			scope, err := promptScope(config, request)
+			if err != nil {
+				return nil, err
+			}
+
+			pipeline, err := client.Pipelines.Get(ctx, scope, pipelineID)
+			if err != nil {
+				return nil, errors.New(describeAPIError(err, "get pipeline", scope))
+			}
+			executions, err := client.Pipelines.ListExecutions(ctx, scope, &dto.PipelineExecutionOptions{
+				PaginationOptions:  dto.PaginationOptions{Size: promptRecentExecutions},
+				PipelineIdentifier: pipelineID,
+			})
+			if err != nil {
+				return nil, errors.New(describeAPIError(err, "list executions", scope))
+			}
+
+			yaml := pipeline.Data.YamlPipeline
+			if pipeline.Data.ResolvedTemplatesPipelineYaml != "" {
+				// templates are only referenced in the YAML, the resolved YAML shows what actually runs
+				yaml = pipeline.Data.ResolvedTemplatesPipelineYaml
+			}
+			uri := scopedResourceURI(scope, "pipelines", pipelineID)
+			recent, err := jsonPromptText("Recent executions", executions.Data.Content)
+			if err != nil {
+				return nil, err
+			}
+
+			instructions := fmt.Sprintf(`Explain what the Harness pipeline %s does.
+
+Its YAML, with templates resolved, and its %d most recent executions are attached.
+1. Summarize the purpose of the pipeline in a few sentences.
+2. Walk through its stages and steps in order: what each does, what it deploys or builds, and the conditions, approvals, failure strategies and parallelism that affect how it runs.
+3. List the runtime inputs, variables, connectors, secrets and templates it depends on.
+4. Point out anything notable in its recent executions, such as recurring failures or slow runs.`,
+				pipelineID, promptRecentExecutions)
+
+			return mcp.NewGetPromptResult(
+				fmt.Sprintf("Explain pipeline %s", pipelineID),
+				[]mcp.PromptMessage{
+					mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(instructions)),
+					mcp.NewPromptMessage(mcp.RoleUser, mcp.NewEmbeddedResource(mcp.TextResourceContents{
+						URI:      uri,
+						MIMEType: "application/yaml",
+						Text:     yaml,
+					})),
+					mcp.NewPromptMessage(mcp.RoleUser, recent),
+				},
+			), nil
+		}
+}
+
+// ReviewPullRequestPrompt creates a prompt for reviewing a pull request
+func ReviewPullRequestPrompt(config *config.Config, client *client.Client) (prompt mcp.Prompt, handler server.PromptHandlerFunc) {
+	return mcp.NewPrompt("review_pull_request",
+			mcp.WithPromptDescription("Review a pull request: its description, its changes and the state of its checks"),
+			withPullRequestArguments(),
+			withPromptScope(),
+		),
+		func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			repoID, prNumber, err := pullRequestPromptArguments(request)
+			if err != nil {
+				return nil, err
+			}
+			scope, err := promptScope(config, request)
+			if err != nil {
+				return nil, err
+			}
+
+			pr, checks, err := fetchPullRequestWithChecks(ctx, client, scope, repoID, prNumber)
+			if err != nil {
+				return nil, err
+			}
+
+			uri := scopedResourceURI(scope, "repos", repoID, "pullreq", strconv.Itoa(prNumber))
+			prResource, err := jsonPromptResource(uri, pr)
+			if err != nil {
+				return nil, err
+			}
+			checksText, err := jsonPromptText("Status checks", checks)
+			if err != nil {
+				return nil, err
+			}
+
+			instructions := fmt.Sprintf(`Review pull request #%d "%s" of the Harness Code repository %s, which merges %s into %s.
+
+The pull request and the status checks of its source commit %s are attached.
+1. Check that the description explains what the change does and why.
+2. Review the changes between the merge base %s and the source commit for bugs, security issues, missing tests and readability. Read the changed files of the source commit with the repository file resources or tools.
+3. Take the failing or pending checks into account.
+Finish with a verdict: approve, or request changes with a list of the changes needed.`,
+				prNumber, pr.Title, repoID, pr.SourceBranch, pr.TargetBranch, pr.SourceSha, pr.MergeBaseSha)
+
+			return mcp.NewGetPromptResult(
+				fmt.Sprintf("Review pull request %s/%d", repoID, prNumber),
+				[]mcp.PromptMessage{
+					mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(instructions)),
+					mcp.NewPromptMessage(mcp.RoleUser, prResource),
+					mcp.NewPromptMessage(mcp.RoleUser, checksText),
+				},
+			), nil
+		}
+}
+
+// WhyIsPullRequestBlockedPrompt creates a prompt for finding out what prevents a pull request from being merged
+func WhyIsPullRequestBlockedPrompt(config *config.Config, client *client.Client) (prompt mcp.Prompt, handler server.PromptHandlerFunc) {
+	return mcp.NewPrompt("why_is_pr_blocked",
+			mcp.WithPromptDescription("Find out what prevents a pull request from being merged and how to unblock it"),
+			withPullRequestArguments(),
+			withPromptScope(),
+		),
+		func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			repoID, prNumber, err := pullRequestPromptArguments(request)
+			if err != nil {
+				return nil, err
+			}
+			scope, err := promptScope(config, request)
+			if err != nil {
+				return nil, err
+			}
+
+			pr, checks, err := fetchPullRequestWithChecks(ctx, client, scope, repoID, prNumber)
+			if err != nil {
+				return nil, err
+			}
+
+			uri := scopedResourceURI(scope, "repos", repoID, "pullreq", strconv.Itoa(prNumber))
+			prResource, err := jsonPromptResource(uri, pr)
+			if err != nil {
+				return nil, err
+			}
+			checksText, err := jsonPromptText("Status checks", checks)
+			if err != nil {
+				return nil, err
+			}
+
+			blockers := "None found in the pull request and its checks, the repository rules or missing approvals may block it."
+			if found := pullRequestBlockers(pr, checks); len(found) > 0 {
+				blockers = "- " + strings.Join(found, "\n- ")
+			}
+
+			instructions := fmt.Sprintf(`Explain why pull request #%d "%s" of the Harness Code repository %s cannot be merged into %s, and how to unblock it.
+
+The pull request and the status checks of its source commit are attached. Blockers found in them:
+%s
+
+For each blocker, explain its cause and the concrete steps to resolve it, such as fixing and re-running a failed check or resolving conflicts by merging or rebasing onto %s. Order the steps so that the pull request can be merged once they are done.`,
+				prNumber, pr.Title, repoID, pr.TargetBranch, blockers, pr.TargetBranch)
+
+			return mcp.NewGetPromptResult(
+				fmt.Sprintf("Why is pull request %s/%d blocked", repoID, prNumber),
+				[]mcp.PromptMessage{
+					mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(instructions)),
+					mcp.NewPromptMessage(mcp.RoleUser, prResource),
+					mcp.NewPromptMessage(mcp.RoleUser, checksText),
+				},
+			), nil
+		}
+}
+
+// fetchPullRequestWithChecks fetches a pull request and the status checks of its source commit.
+func fetchPullRequestWithChecks(ctx context.Context, client *client.Client, scope dto.Scope, repoID string, prNumber int) (*dto.PullRequest, *dto.PullRequestChecksResponse, error) {
+	pr, err := client.PullRequests.Get(ctx, scope, repoID, prNumber)
+	if err != nil {
+		return nil, nil, errors.New(describeAPIError(err, "get pull request", scope))
+	}
+	checks, err := client.PullRequests.GetChecks(ctx, scope, repoID, prNumber)
+	if err != nil {
+		return nil, nil, errors.New(describeAPIError(err, "get pull request checks", scope))
+	}
+	return pr, checks, nil
+}
+
+// pullRequestBlockers lists what prevents a pull request from being merged, as far as the pull request
+// and its checks tell.
+func pullRequestBlockers(pr *dto.PullRequest, checks *dto.PullRequestChecksResponse) []string {
+	var blockers []string
+	if !strings.EqualFold(pr.State, "open") {
+		blockers = append(blockers, fmt.Sprintf("The pull request is %s", pr.State))
+	}
+	if pr.IsDraft {
+		blockers = append(blockers, "The pull request is a draft")
+	}
+	if len(pr.MergeConflicts) > 0 {
+		blockers = append(blockers, fmt.Sprintf("Merge conflicts with %s in: %s", pr.TargetBranch, strings.Join(pr.MergeConflicts, ", ")))
+	} else if pr.MergeCheckStatus != "" && !strings.EqualFold(pr.MergeCheckStatus, "mergeable") {
+		blockers = append(blockers, fmt.Sprintf("The merge check status is %s", pr.MergeCheckStatus))
+	}
+
+	for _, info := range checks.Checks {
+		if !info.Required {
+			continue
+		}
+		switch strings.ToLower(info.Check.Status) {
+		case "success":
+		case "pending", "running":
+			blockers = append(blockers, fmt.Sprintf("The required check %s is still %s", info.Check.Identifier, info.Check.Status))
+		default:
+			blockers = append(blockers, fmt.Sprintf("The required check %s has status %s: %s", info.Check.Identifier, info.Check.Status, info.Check.Summary))
+		}
+	}
+
+	return blockers
+}
+
+// withPromptScope adds org_id and project_id as optional arguments, defaulting to the configured ones.
+func withPromptScope() mcp.PromptOption {
+	return func(prompt *mcp.Prompt) {
+		mcp.WithArgument("org_id", mcp.ArgumentDescription("The ID of the organization, defaults to the configured one"))(prompt)
+		mcp.WithArgument("project_id", mcp.ArgumentDescription("The ID of the project, defaults to the configured one"))(prompt)
+	}
+}
+
+// withPullRequestArguments adds the arguments identifying a pull request.
+func withPullRequestArguments() mcp.PromptOption {
+	return func(prompt *mcp.Prompt) {
+		mcp.WithArgument("repo_id", mcp.ArgumentDescription("The ID of the repository"), mcp.RequiredArgument())(prompt)
+		mcp.WithArgument("pr_number", mcp.ArgumentDescription("The number of the pull request"), mcp.RequiredArgument())(prompt)
+	}
+}
+
+// pullRequestPromptArguments returns the repository and number of the pull request a prompt is about.
+func pullRequestPromptArguments(request mcp.GetPromptRequest) (string, int, error) {
+	repoID, err := requiredPromptArgument(request, "repo_id")
+	if err != nil {
+		return "", 0, err
+	}
+	number, err := requiredPromptArgument(request, "pr_number")
+	if err != nil {
+		return "", 0, err
+	}
+	prNumber, err := strconv.Atoi(number)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid pull request number: %s", number)
+	}
+	return repoID, prNumber, nil
+}
+
+// requiredPromptArgument returns an argument of a prompt, or an error if it is missing.
+func requiredPromptArgument(request mcp.GetPromptRequest, name string) (string, error) {
+	value := strings.TrimSpace(request.Params.Arguments[name])
+	if value == "" {
+		return "", fmt.Errorf("missing required argument: %s", name)
+	}
+	return value, nil
+}
+
+// promptScope builds the scope of a prompt from the config and the org_id and project_id arguments,
+// which take precedence.
+func promptScope(config *config.Config, request mcp.GetPromptRequest) (dto.Scope, error) {
+	if config.AccountID == "" {
+		return dto.Scope{}, fmt.Errorf("account ID is required")
+	}
+
+	scope := dto.Scope{
+		AccountID: config.AccountID,
+		OrgID:     config.OrgID,
+		ProjectID: config.ProjectID,
+	}
+	if org := request.Params.Arguments["org_id"]; org != "" {
+		scope.OrgID = org
+	}
+	if project := request.Params.Arguments["project_id"]; project != "" {
+		scope.ProjectID = project
+	}
+
+	if scope.OrgID == "" || scope.ProjectID == "" {
+		return scope, fmt.Errorf("org ID and project ID are required")
+	}
+	return scope, nil
+}
+
+// scopedResourceURI builds the URI of a resource in the org and project of scope, see resources.go.
+func scopedResourceURI(scope dto.Scope, segments ...string) string {
+	return "harness://" + scope.OrgID + "/" + scope.ProjectID + "/" + strings.Join(segments, "/")
+}
+
+// jsonPromptText formats v as JSON text content titled title.
+func jsonPromptText(title string, v any) (mcp.TextContent, error) {
+	r, err := json.Marshal(v)
+	if err != nil {
+		return mcp.TextContent{}, fmt.Errorf("failed to marshal %s: %w", strings.ToLower(title), err)
+	}
+	return mcp.NewTextContent(title + ":\n" + string(r)), nil
+}
+
+// jsonPromptResource embeds v as a JSON resource in a prompt message.
+func jsonPromptResource(uri string, v any) (mcp.EmbeddedResource, error) {
+	contents, err := jsonResourceContents(uri, v)
+	if err != nil {
+		return mcp.EmbeddedResource{}, err
+	}
+	return mcp.NewEmbeddedResource(contents[0]), nil
+}
@@ -2,7 +2,6 @@ package harness
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -18,6 +17,8 @@ import (
 func GetPullRequestTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_pull_request",
 			mcp.WithDescription("Get details of a specific pull request in a Harness repository."),
+			WithOutputSchema[dto.PullRequest](),
+			WithResponseShaping(),
 			mcp.WithString("repo_id",
 				mcp.Required(),
 				mcp.Description("The ID of the repository"),
@@ -47,15 +48,10 @@ func GetPullRequestTool(config *config.Config, client *client.Client) (tool mcp.
 
 			data, err := client.PullRequests.Get(ctx, scope, repoID, prNumber)
 			if err != nil {
-				return nil, fmt.Errorf("failed to get pull request: %w", err)
+				return apiErrorResult(err, "get pull request", scope), nil
 			}
 
-			r, err := json.Marshal(data)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal pull request: %w", err)
-			}
-
-			return mcp.NewToolResultText(string(r)), nil
+			return structuredResult(data, "pull request")
 		}
 }
 
@@ -64,6 +60,8 @@ func GetPullRequestTool(config *config.Config, client *client.Client) (tool mcp.
 func ListPullRequestsTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("list_pull_requests",
 			mcp.WithDescription("List pull requests in a Harness repository."),
+			WithListOutputSchema[*dto.PullRequest](),
+			WithResponseShaping(),
 			mcp.WithString("repo_id",
 				mcp.Required(),
 				mcp.Description("The ID of the repository"),
@@ -176,15 +174,10 @@ func ListPullRequestsTool(config *config.Config, client *client.Client) (tool mc
 
 			data, err := client.PullRequests.List(ctx, scope, repoID, opts)
 			if err != nil {
-				return nil, fmt.Errorf("failed to list pull requests: %w", err)
-			}
-
-			r, err := json.Marshal(data)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal pull request list: %w", err)
+				return apiErrorResult(err, "list pull requests", scope), nil
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return structuredListResult(data, "pull request list")
 		}
 }
 
@@ -219,6 +212,8 @@ func splitAndTrim(s, sep string) []string {
 func GetPullRequestChecksTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_pull_request_checks",
 			mcp.WithDescription("Get status checks for a specific pull request in a Harness repository."),
+			WithOutputSchema[dto.PullRequestChecksResponse](),
+			WithResponseShaping(),
 			mcp.WithString("repo_identifier",
 				mcp.Required(),
 				mcp.Description("The identifier of the repository"),
@@ -248,15 +243,10 @@ func GetPullRequestChecksTool(config *config.Config, client *client.Client) (too
 
 			data, err := client.PullRequests.GetChecks(ctx, scope, repoIdentifier, prNumber)
 			if err != nil {
-				return nil, fmt.Errorf("failed to get pull request checks: %w", err)
+				return apiErrorResult(err, "get pull request checks", scope), nil
 			}
 
-			r, err := json.Marshal(data)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal pull request checks: %w", err)
-			}
-
-			return mcp.NewToolResultText(string(r)), nil
+			return structuredResult(data, "pull request checks")
 		}
 }
 
@@ -264,6 +254,10 @@ func GetPullRequestChecksTool(config *config.Config, client *client.Client) (too
 func CreatePullRequestTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("create_pull_request",
 			mcp.WithDescription("Create a new pull request in a Harness repository."),
+			WithOutputSchema[dto.PullRequest](),
+			WithResponseShaping(),
+			// creating a pull request changes nothing that exists already
+			mcp.WithDestructiveHintAnnotation(false),
 			mcp.WithString("repo_identifier",
 				mcp.Required(),
 				mcp.Description("The identifier of the repository"),
@@ -335,14 +329,9 @@ func CreatePullRequestTool(config *config.Config, client *client.Client) (tool m
 
 			data, err := client.PullRequests.Create(ctx, scope, repoIdentifier, createRequest)
 			if err != nil {
-				return nil, fmt.Errorf("failed to create pull request: %w", err)
-			}
-
-			r, err := json.Marshal(data)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal pull request: %w", err)
+				return apiErrorResult(err, "create pull request", scope), nil
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return structuredResult(data, "pull request")
 		}
 }
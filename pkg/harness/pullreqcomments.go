@@ -0,0 +1,351 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/harness/harness-mcp/client"
+	"github.com/harness/harness-mcp/client/dto"
+	"github.com/harness/harness-mcp/cmd/harness-mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sides of the diff lines of code comments are anchored on
+const (
+	lineSideNew = "new"
+	lineSideOld = "old"
+)
+
+// ListPullRequestActivitiesTool creates a tool for listing the activities of a pull request
+func ListPullRequestActivitiesTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("list_pull_request_activities",
+			mcp.WithDescription("List the activities of a pull request in a Harness repository, oldest first: comments, comments on code anchored on lines of a file (see code_comment), and changes like state changes, reviews and pushes. Replies have the ID of the comment they reply to as parent_id, and resolved comments have a resolved time."),
+			WithListOutputSchema[*dto.PullRequestActivity](),
+			WithResponseShaping(),
+			mcp.WithString("repo_id",
+				mcp.Required(),
+				mcp.Description("The ID of the repository"),
+			),
+			mcp.WithNumber("pr_number",
+				mcp.Required(),
+				mcp.Description("The number of the pull request"),
+			),
+			mcp.WithString("kind",
+				mcp.Description("Optional kind of activities to list: comment for comments, change-comment for comments on code, system for changes of the pull request"),
+				mcp.Enum(dto.PullRequestActivityKindComment, dto.PullRequestActivityKindChangeComment, dto.PullRequestActivityKindSystem),
+			),
+			mcp.WithNumber("after",
+				mcp.Description("Optional time in milliseconds since the epoch, to list the activities created after it"),
+			),
+			mcp.WithNumber("before",
+				mcp.Description("Optional time in milliseconds since the epoch, to list the activities created before it"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Optional maximum number of activities to list"),
+				mcp.Min(1),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			repoID, err := requiredParam[string](request, "repo_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			prNumberFloat, err := requiredParam[float64](request, "pr_number")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			prNumber := int(prNumberFloat)
+
+			opts := &dto.PullRequestActivityOptions{}
+
+			opts.Kind, err = OptionalParam[string](request, "kind")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			after, err := OptionalParam[float64](request, "after")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			opts.After = int64(after)
+
+			before, err := OptionalParam[float64](request, "before")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			opts.Before = int64(before)
+
+			opts.Limit, err = OptionalIntParam(request, "limit")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			data, err := client.PullRequests.ListActivities(ctx, scope, repoID, prNumber, opts)
+			if err != nil {
+				return apiErrorResult(err, "list pull request activities", scope), nil
+			}
+
+			return structuredListResult(data, "pull request activities")
+		}
+}
+
+// CreatePullRequestCommentTool creates a tool for commenting on a pull request, or on lines of one of its files
+func CreatePullRequestCommentTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("create_pull_request_comment",
+			mcp.WithDescription("Comment on a pull request in a Harness repository. With a path and lines, the comment is anchored on these lines of the file, as changed by the pull request at its current source commit."),
+			WithOutputSchema[dto.PullRequestActivity](),
+			WithResponseShaping(),
+			// commenting changes nothing that exists already
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("repo_id",
+				mcp.Required(),
+				mcp.Description("The ID of the repository"),
+			),
+			mcp.WithNumber("pr_number",
+				mcp.Required(),
+				mcp.Description("The number of the pull request"),
+			),
+			mcp.WithString("text",
+				mcp.Required(),
+				mcp.Description("The text of the comment, in markdown"),
+			),
+			mcp.WithString("path",
+				mcp.Description("Optional path of the file to comment on, for a comment on code"),
+			),
+			mcp.WithNumber("line_start",
+				mcp.Description("The first line the comment on code is about, required with path"),
+				mcp.Min(1),
+			),
+			mcp.WithNumber("line_end",
+				mcp.Description("Optional last line the comment on code is about, defaults to line_start"),
+				mcp.Min(1),
+			),
+			mcp.WithString("line_side",
+				mcp.Description("Whether the lines are numbered in the new version of the file, as changed by the pull request, or in the old one, e.g. to comment on removed lines"),
+				mcp.Enum(lineSideNew, lineSideOld),
+				mcp.DefaultString(lineSideNew),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			repoID, err := requiredParam[string](request, "repo_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			prNumberFloat, err := requiredParam[float64](request, "pr_number")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			prNumber := int(prNumberFloat)
+
+			text, err := requiredParam[string](request, "text")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			path, err := OptionalParam[string](request, "path")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			lineStart, err := OptionalIntParam(request, "line_start")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			lineEnd, err := OptionalIntParamWithDefault(request, "line_end", lineStart)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			lineSide, err := OptionalParam[string](request, "line_side")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			switch {
+			case path == "" && lineStart != 0:
+				return mcp.NewToolResultError("path is required to comment on lines"), nil
+			case path != "" && lineStart == 0:
+				return mcp.NewToolResultError("line_start is required to comment on a file"), nil
+			case lineEnd < lineStart:
+				return mcp.NewToolResultError("line_end must not be before line_start"), nil
+			}
+			switch lineSide {
+			case "", lineSideNew, lineSideOld:
+			default:
+				return mcp.NewToolResultError(fmt.Sprintf("invalid line_side: %s", lineSide)), nil
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			comment := &dto.CreatePullRequestComment{Text: text}
+			if path != "" {
+				// lines are numbered in the commits the pull request is at
+				pr, err := client.PullRequests.Get(ctx, scope, repoID, prNumber)
+				if err != nil {
+					return apiErrorResult(err, "get pull request", scope), nil
+				}
+
+				newLines := lineSide != lineSideOld
+				comment.SourceCommitSha = pr.SourceSha
+				comment.TargetCommitSha = pr.MergeBaseSha
+				comment.Path = path
+				comment.LineStart, comment.LineStartNew = lineStart, newLines
+				comment.LineEnd, comment.LineEndNew = lineEnd, newLines
+			}
+
+			data, err := client.PullRequests.CreateComment(ctx, scope, repoID, prNumber, comment)
+			if err != nil {
+				return apiErrorResult(err, "create pull request comment", scope), nil
+			}
+
+			return structuredResult(data, "pull request comment")
+		}
+}
+
+// ReplyToPullRequestCommentTool creates a tool for replying to a comment of a pull request
+func ReplyToPullRequestCommentTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("reply_to_pull_request_comment",
+			mcp.WithDescription("Reply to a comment of a pull request in a Harness repository, adding to its thread."),
+			WithOutputSchema[dto.PullRequestActivity](),
+			WithResponseShaping(),
+			// replying changes nothing that exists already
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("repo_id",
+				mcp.Required(),
+				mcp.Description("The ID of the repository"),
+			),
+			mcp.WithNumber("pr_number",
+				mcp.Required(),
+				mcp.Description("The number of the pull request"),
+			),
+			mcp.WithNumber("comment_id",
+				mcp.Required(),
+				mcp.Description("The ID of the comment starting the thread, as listed by list_pull_request_activities"),
+			),
+			mcp.WithString("text",
+				mcp.Required(),
+				mcp.Description("The text of the reply, in markdown"),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			repoID, err := requiredParam[string](request, "repo_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			prNumberFloat, err := requiredParam[float64](request, "pr_number")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			prNumber := int(prNumberFloat)
+
+			commentIDFloat, err := requiredParam[float64](request, "comment_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			commentID := int(commentIDFloat)
+
+			text, err := requiredParam[string](request, "text")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			data, err := client.PullRequests.CreateComment(ctx, scope, repoID, prNumber, &dto.CreatePullRequestComment{
+				Text:     text,
+				ParentID: commentID,
+			})
+			if err != nil {
+				return apiErrorResult(err, "reply to pull request comment", scope), nil
+			}
+
+			return structuredResult(data, "pull request comment")
+		}
+}
+
+// ResolvePullRequestCommentTool creates a tool for resolving a comment thread of a pull request, or reopening it
+func ResolvePullRequestCommentTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("resolve_pull_request_comment",
+			mcp.WithDescription("Resolve a comment thread of a pull request in a Harness repository, or reopen it with resolved set to false."),
+			WithOutputSchema[dto.PullRequestActivity](),
+			WithResponseShaping(),
+			// resolving can be undone, and resolving twice is the same as once
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithString("repo_id",
+				mcp.Required(),
+				mcp.Description("The ID of the repository"),
+			),
+			mcp.WithNumber("pr_number",
+				mcp.Required(),
+				mcp.Description("The number of the pull request"),
+			),
+			mcp.WithNumber("comment_id",
+				mcp.Required(),
+				mcp.Description("The ID of the comment starting the thread, as listed by list_pull_request_activities"),
+			),
+			mcp.WithBoolean("resolved",
+				mcp.Description("Whether to resolve the thread, or reopen it"),
+				mcp.DefaultBool(true),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			repoID, err := requiredParam[string](request, "repo_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			prNumberFloat, err := requiredParam[float64](request, "pr_number")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			prNumber := int(prNumberFloat)
+
+			commentIDFloat, err := requiredParam[float64](request, "comment_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			commentID := int(commentIDFloat)
+
+			resolved, ok, err := OptionalParamOK[bool](request, "resolved")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			status := dto.PullRequestCommentStatusResolved
+			if ok && !resolved {
+				status = dto.PullRequestCommentStatusActive
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			data, err := client.PullRequests.UpdateCommentStatus(ctx, scope, repoID, prNumber, commentID, status)
+			if err != nil {
+				return apiErrorResult(err, "update pull request comment status", scope), nil
+			}
+
+			return structuredResult(data, "pull request comment")
+		}
+}
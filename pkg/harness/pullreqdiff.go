@@ -0,0 +1,282 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/harness/harness-mcp/client"
+	"github.com/harness/harness-mcp/client/dto"
+	"github.com/harness/harness-mcp/cmd/harness-mcp-server/config"
+	"github.com/harness/harness-mcp/pkg/textutil"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	defaultDiffMaxBytes = 50000
+	defaultDiffPageSize = 20
+	maxDiffPageSize     = 100
+
+	// a diff that does not fit in the rest of the budget is cut if at least this much of it fits
+	minDiffCutBytes = 1024
+)
+
+// pullRequestDiff is the result of getting the diff of a pull request
+type pullRequestDiff struct {
+	// The diff is between these commits
+	MergeBaseSha string `json:"merge_base_sha"`
+	SourceSha    string `json:"source_sha"`
+	// Totals of the changed files matching the paths, across pages
+	TotalFiles int                   `json:"total_files"`
+	Additions  int                   `json:"additions"`
+	Deletions  int                   `json:"deletions"`
+	Page       int                   `json:"page"`
+	HasMore    bool                  `json:"has_more"`
+	Files      []pullRequestDiffFile `json:"files"`
+}
+
+// pullRequestDiffFile is a file changed by a pull request
+type pullRequestDiffFile struct {
+	Path      string `json:"path"`
+	OldPath   string `json:"old_path,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	IsBinary  bool   `json:"is_binary,omitempty"`
+	// The unified diff of the file, cut at a line if DiffTruncated is set
+	Diff          string `json:"diff,omitempty"`
+	DiffTruncated bool   `json:"diff_truncated,omitempty"`
+	// Why the diff is missing, e.g. the file is binary or the max_bytes budget ran out
+	DiffOmitted string `json:"diff_omitted,omitempty"`
+}
+
+// GetPullRequestDiffTool creates a tool for getting the files changed by a pull request and their diffs
+func GetPullRequestDiffTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("get_pull_request_diff",
+			mcp.WithDescription("Get the files changed by a pull request in a Harness repository, with their additions, deletions and status, and their unified diffs between the merge base and the source commit of the pull request. Files are paginated, and diffs are returned until max_bytes is reached. Use paths to get the diffs of specific files."),
+			WithOutputSchema[pullRequestDiff](),
+			WithResponseShaping(),
+			mcp.WithString("repo_id",
+				mcp.Required(),
+				mcp.Description("The ID of the repository"),
+			),
+			mcp.WithNumber("pr_number",
+				mcp.Required(),
+				mcp.Description("The number of the pull request"),
+			),
+			mcp.WithString("paths",
+				mcp.Description("Optional comma-separated paths of the files to return: file paths, directories, or glob patterns like *.go or pkg/*/main.go. Patterns without a slash match the names of files in any directory"),
+			),
+			mcp.WithBoolean("include_diff",
+				mcp.Description("Whether to return the diffs of the files, or only list them"),
+				mcp.DefaultBool(true),
+			),
+			mcp.WithNumber("max_bytes",
+				mcp.Description("Maximum number of bytes of diffs to return. The diffs of the files after the budget runs out are omitted"),
+				mcp.DefaultNumber(defaultDiffMaxBytes),
+				mcp.Min(minDiffCutBytes),
+			),
+			mcp.WithNumber("page",
+				mcp.Description("Page number of the files - page 0 is the first page"),
+				mcp.Min(0),
+				mcp.DefaultNumber(0),
+			),
+			mcp.WithNumber("size",
+				mcp.Description("Number of files per page"),
+				mcp.DefaultNumber(defaultDiffPageSize),
+				mcp.Max(maxDiffPageSize),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			repoID, err := requiredParam[string](request, "repo_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			prNumberFloat, err := requiredParam[float64](request, "pr_number")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			prNumber := int(prNumberFloat)
+
+			pathsParam, err := OptionalParam[string](request, "paths")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			paths := splitAndTrim(pathsParam, ",")
+			for _, pattern := range paths {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid path pattern: %s", pattern)), nil
+				}
+			}
+
+			includeDiff := true
+			if v, ok, err := OptionalParamOK[bool](request, "include_diff"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			} else if ok {
+				includeDiff = v
+			}
+
+			maxBytes, err := OptionalIntParamWithDefault(request, "max_bytes", defaultDiffMaxBytes)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			page, err := OptionalIntParam(request, "page")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			size, err := OptionalIntParamWithDefault(request, "size", defaultDiffPageSize)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			page, size = max(page, 0), min(max(size, 1), maxDiffPageSize)
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			pr, err := client.PullRequests.Get(ctx, scope, repoID, prNumber)
+			if err != nil {
+				return apiErrorResult(err, "get pull request", scope), nil
+			}
+			if pr.MergeBaseSha == "" || pr.SourceSha == "" {
+				return mcp.NewToolResultError(fmt.Sprintf("pull request %d has no merge base, its diff cannot be computed", prNumber)), nil
+			}
+
+			// list the files without their diffs first, the diffs are only fetched for the files of the page
+			files, err := client.Repositories.GetDiff(ctx, scope, repoID, pr.MergeBaseSha, pr.SourceSha, false, nil)
+			if err != nil {
+				return apiErrorResult(err, "get pull request diff", scope), nil
+			}
+
+			result := pullRequestDiff{
+				MergeBaseSha: pr.MergeBaseSha,
+				SourceSha:    pr.SourceSha,
+				Page:         page,
+				Files:        []pullRequestDiffFile{},
+			}
+			var matching []*dto.FileDiff
+			for _, file := range files {
+				if matchesDiffPaths(file, paths) {
+					matching = append(matching, file)
+					result.Additions += file.Additions
+					result.Deletions += file.Deletions
+				}
+			}
+			result.TotalFiles = len(matching)
+
+			start := min(page*size, len(matching))
+			end := min(start+size, len(matching))
+			result.HasMore = end < len(matching)
+
+			var patches map[string]string
+			if includeDiff {
+				patches, err = fetchDiffPatches(ctx, client, scope, repoID, pr, matching[start:end])
+				if err != nil {
+					return apiErrorResult(err, "get pull request diff", scope), nil
+				}
+			}
+
+			budget := maxBytes
+			for _, file := range matching[start:end] {
+				diffFile := pullRequestDiffFile{
+					Path:      file.Path,
+					OldPath:   file.OldPath,
+					Status:    file.Status,
+					Additions: file.Additions,
+					Deletions: file.Deletions,
+					IsBinary:  file.IsBinary,
+				}
+				if diffFile.OldPath == diffFile.Path {
+					diffFile.OldPath = ""
+				}
+
+				patch, hasPatch := patches[file.Path]
+				switch {
+				case !includeDiff:
+				case file.IsBinary:
+					diffFile.DiffOmitted = "binary file"
+				case !hasPatch:
+					diffFile.DiffOmitted = "diff not returned by the server"
+				case len(patch) <= budget:
+					diffFile.Diff = patch
+					budget -= len(patch)
+				case budget >= minDiffCutBytes:
+					// cut the diff at the end of a line, or at a character boundary if its first line is longer
+					// than the budget. The rest of the files have no budget left
+					cut := patch[:textutil.RunePrefixLen(patch, budget)]
+					if i := strings.LastIndexByte(cut, '\n'); i >= 0 {
+						cut = cut[:i+1]
+					}
+					diffFile.Diff, diffFile.DiffTruncated = cut, true
+					budget = 0
+				default:
+					diffFile.DiffOmitted = "max_bytes reached, get it with paths"
+					budget = 0
+				}
+
+				result.Files = append(result.Files, diffFile)
+			}
+
+			return structuredResult(result, "pull request diff")
+		}
+}
+
+// fetchDiffPatches fetches the unified diffs of the given changed files, except binary files, by path.
+func fetchDiffPatches(ctx context.Context, client *client.Client, scope dto.Scope, repoID string, pr *dto.PullRequest, files []*dto.FileDiff) (map[string]string, error) {
+	var paths []string
+	for _, file := range files {
+		if !file.IsBinary {
+			paths = append(paths, file.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	diffs, err := client.Repositories.GetDiff(ctx, scope, repoID, pr.MergeBaseSha, pr.SourceSha, true, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	patches := make(map[string]string, len(diffs))
+	for _, diff := range diffs {
+		patches[diff.Path] = string(diff.Patch)
+	}
+	return patches, nil
+}
+
+// matchesDiffPaths reports whether a changed file, at its new or old path, matches one of the path filters.
+// All files match if there is no filter.
+func matchesDiffPaths(file *dto.FileDiff, filters []string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, filter := range filters {
+		for _, p := range []string{file.Path, file.OldPath} {
+			if p != "" && matchesDiffPath(p, filter) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesDiffPath reports whether a file path matches a filter: the path itself, a directory
+// containing it, or a glob pattern. Patterns without a slash match the name of the file.
+func matchesDiffPath(p, filter string) bool {
+	filter = strings.TrimPrefix(filter, "/")
+	if p == filter || strings.HasPrefix(p, strings.TrimSuffix(filter, "/")+"/") {
+		return true
+	}
+	if !strings.Contains(filter, "/") {
+		p = path.Base(p)
+	}
+	matched, _ := path.Match(filter, p)
+	return matched
+}
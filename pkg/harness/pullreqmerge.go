@@ -0,0 +1,216 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/harness/harness-mcp/client"
+	"github.com/harness/harness-mcp/client/dto"
+	"github.com/harness/harness-mcp/cmd/harness-mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// mergeResult is the result of merging a pull request, or of checking whether it can be merged
+type mergeResult struct {
+	DryRun bool   `json:"dry_run"`
+	Method string `json:"method"`
+	// Whether the pull request can be merged with the method
+	Mergeable bool `json:"mergeable"`
+	Merged    bool `json:"merged"`
+	// The commit the pull request was merged as, and whether its source branch was deleted
+	SHA           string `json:"sha,omitempty"`
+	BranchDeleted bool   `json:"branch_deleted,omitempty"`
+	// The source commit that was merged, or checked in a dry run
+	SourceSha string `json:"source_sha,omitempty"`
+	// What prevents the merge, in a dry run
+	Blockers       []string             `json:"blockers,omitempty"`
+	AllowedMethods []string             `json:"allowed_methods,omitempty"`
+	RuleViolations []dto.RuleViolations `json:"rule_violations,omitempty"`
+}
+
+// MergePullRequestTool creates a tool for merging a pull request
+func MergePullRequestTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("merge_pull_request",
+			mcp.WithDescription("Merge a pull request in a Harness repository. Use dry_run first to check whether the branch rules, required checks and approvals allow the merge, and with which methods. The pull request is merged at its current source commit, unless source_sha is passed."),
+			WithOutputSchema[mergeResult](),
+			WithResponseShaping(),
+			mcp.WithString("repo_id",
+				mcp.Required(),
+				mcp.Description("The ID of the repository"),
+			),
+			mcp.WithNumber("pr_number",
+				mcp.Required(),
+				mcp.Description("The number of the pull request"),
+			),
+			mcp.WithString("merge_method",
+				mcp.Description("The method of merging the pull request"),
+				mcp.Enum(dto.MergeMethodMerge, dto.MergeMethodSquash, dto.MergeMethodRebase, dto.MergeMethodFastForward),
+				mcp.DefaultString(dto.MergeMethodMerge),
+			),
+			mcp.WithBoolean("dry_run",
+				mcp.Description("Only check whether the pull request can be merged, without merging it"),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithBoolean("delete_source_branch",
+				mcp.Description("Whether to delete the source branch once the pull request is merged"),
+				mcp.DefaultBool(false),
+			),
+			mcp.WithString("source_sha",
+				mcp.Description("Optional commit the source branch must be at, e.g. the one that was reviewed. The merge fails if commits were pushed since"),
+			),
+			mcp.WithString("title",
+				mcp.Description("Optional title of the merge or squash commit"),
+			),
+			mcp.WithString("message",
+				mcp.Description("Optional message of the merge or squash commit"),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			repoID, err := requiredParam[string](request, "repo_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			prNumberFloat, err := requiredParam[float64](request, "pr_number")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			prNumber := int(prNumberFloat)
+
+			method, err := OptionalParam[string](request, "merge_method")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			switch method {
+			case "":
+				method = dto.MergeMethodMerge
+			case dto.MergeMethodMerge, dto.MergeMethodSquash, dto.MergeMethodRebase, dto.MergeMethodFastForward:
+			default:
+				return mcp.NewToolResultError(fmt.Sprintf("invalid merge_method: %s", method)), nil
+			}
+
+			dryRun, err := OptionalParam[bool](request, "dry_run")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			deleteSourceBranch, err := OptionalParam[bool](request, "delete_source_branch")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			sourceSha, err := OptionalParam[string](request, "source_sha")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			title, err := OptionalParam[string](request, "title")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			message, err := OptionalParam[string](request, "message")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			merge := &dto.MergePullRequest{
+				Method:             method,
+				SourceSha:          sourceSha,
+				Title:              title,
+				Message:            message,
+				DeleteSourceBranch: deleteSourceBranch,
+			}
+
+			if !dryRun {
+				if merge.SourceSha == "" {
+					pr, err := client.PullRequests.Get(ctx, scope, repoID, prNumber)
+					if err != nil {
+						return apiErrorResult(err, "get pull request", scope), nil
+					}
+					merge.SourceSha = pr.SourceSha
+				}
+
+				data, err := client.PullRequests.Merge(ctx, scope, repoID, prNumber, merge)
+				if err != nil {
+					return apiErrorResult(err, "merge pull request", scope), nil
+				}
+
+				return structuredResult(mergeResult{
+					Method:         method,
+					Mergeable:      true,
+					Merged:         true,
+					SHA:            data.SHA,
+					BranchDeleted:  data.BranchDeleted,
+					SourceSha:      merge.SourceSha,
+					RuleViolations: data.RuleViolations,
+				}, "merge result")
+			}
+
+			pr, checks, err := fetchPullRequestWithChecks(ctx, client, scope, repoID, prNumber)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if merge.SourceSha == "" {
+				merge.SourceSha = pr.SourceSha
+			}
+
+			result := mergeResult{
+				DryRun:    true,
+				Method:    method,
+				SourceSha: merge.SourceSha,
+				Blockers:  pullRequestBlockers(pr, checks),
+			}
+			if merge.SourceSha != pr.SourceSha {
+				result.Blockers = append(result.Blockers, fmt.Sprintf("The source branch is at %s, not at %s", pr.SourceSha, merge.SourceSha))
+			}
+
+			// only open pull requests can be checked by Harness, the blockers already tell why others cannot be merged
+			if strings.EqualFold(pr.State, "open") {
+				merge.DryRun, merge.DryRunRules = true, true
+				data, err := client.PullRequests.Merge(ctx, scope, repoID, prNumber, merge)
+				if err != nil {
+					return apiErrorResult(err, "check merge of pull request", scope), nil
+				}
+
+				result.AllowedMethods = data.AllowedMethods
+				result.RuleViolations = data.RuleViolations
+				result.Blockers = append(result.Blockers, mergeBlockers(method, data)...)
+				if !data.Mergeable && len(result.Blockers) == 0 {
+					result.Blockers = append(result.Blockers, "Harness reports that the pull request cannot be merged")
+				}
+			}
+			result.Mergeable = len(result.Blockers) == 0
+
+			return structuredResult(result, "merge result")
+		}
+}
+
+// mergeBlockers lists what prevents a pull request from being merged with a method, according to a dry run
+// of the merge. Conflicts and required checks are covered by pullRequestBlockers already.
+func mergeBlockers(method string, data *dto.MergeResponse) []string {
+	var blockers []string
+	if len(data.AllowedMethods) > 0 && !slices.Contains(data.AllowedMethods, method) {
+		blockers = append(blockers, fmt.Sprintf("The branch rules do not allow the %s method, only: %s", method, strings.Join(data.AllowedMethods, ", ")))
+	}
+
+	for _, ruleViolations := range data.RuleViolations {
+		if ruleViolations.Bypassed {
+			continue
+		}
+		for _, violation := range ruleViolations.Violations {
+			blockers = append(blockers, fmt.Sprintf("The rule %s is violated: %s", ruleViolations.Rule.Identifier, violation.Message))
+		}
+	}
+
+	return blockers
+}
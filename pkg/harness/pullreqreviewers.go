@@ -0,0 +1,310 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/harness/harness-mcp/client"
+	"github.com/harness/harness-mcp/client/dto"
+	"github.com/harness/harness-mcp/cmd/harness-mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// pullRequestReviewers is the result of listing the reviewers of a pull request
+type pullRequestReviewers struct {
+	// The current source commit, decisions made on other commits may be outdated
+	SourceSha  string                              `json:"source_sha,omitempty"`
+	Reviewers  []*dto.PullRequestReviewer          `json:"reviewers"`
+	UserGroups []*dto.PullRequestUserGroupReviewer `json:"user_groups"`
+}
+
+// reviewerResult is the result of adding a user or a user group as reviewer
+type reviewerResult struct {
+	Reviewer          *dto.PullRequestReviewer          `json:"reviewer,omitempty"`
+	UserGroupReviewer *dto.PullRequestUserGroupReviewer `json:"user_group_reviewer,omitempty"`
+}
+
+// reviewResult is the result of submitting a review decision
+type reviewResult struct {
+	Decision  string `json:"decision"`
+	CommitSha string `json:"commit_sha"`
+}
+
+// ListPullRequestReviewersTool creates a tool for listing the reviewers of a pull request and their decisions
+func ListPullRequestReviewersTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("list_pull_request_reviewers",
+			mcp.WithDescription("List the users and user groups reviewing a pull request in a Harness repository, with their review decisions: pending, reviewed, approved or changereq (changes requested). Decisions are made on a source commit (sha), and may be outdated if it is not the current source_sha."),
+			WithOutputSchema[pullRequestReviewers](),
+			WithResponseShaping(),
+			mcp.WithString("repo_id",
+				mcp.Required(),
+				mcp.Description("The ID of the repository"),
+			),
+			mcp.WithNumber("pr_number",
+				mcp.Required(),
+				mcp.Description("The number of the pull request"),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			repoID, err := requiredParam[string](request, "repo_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			prNumberFloat, err := requiredParam[float64](request, "pr_number")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			prNumber := int(prNumberFloat)
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			pr, err := client.PullRequests.Get(ctx, scope, repoID, prNumber)
+			if err != nil {
+				return apiErrorResult(err, "get pull request", scope), nil
+			}
+
+			reviewers, err := client.PullRequests.ListReviewers(ctx, scope, repoID, prNumber)
+			if err != nil {
+				return apiErrorResult(err, "list pull request reviewers", scope), nil
+			}
+
+			userGroups, err := client.PullRequests.ListUserGroupReviewers(ctx, scope, repoID, prNumber)
+			if err != nil {
+				return apiErrorResult(err, "list pull request user group reviewers", scope), nil
+			}
+
+			result := pullRequestReviewers{
+				SourceSha:  pr.SourceSha,
+				Reviewers:  reviewers,
+				UserGroups: userGroups,
+			}
+			if result.Reviewers == nil {
+				result.Reviewers = []*dto.PullRequestReviewer{}
+			}
+			if result.UserGroups == nil {
+				result.UserGroups = []*dto.PullRequestUserGroupReviewer{}
+			}
+
+			return structuredResult(result, "pull request reviewers")
+		}
+}
+
+// AddPullRequestReviewerTool creates a tool for adding a user or a user group as reviewer of a pull request
+func AddPullRequestReviewerTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("add_pull_request_reviewer",
+			mcp.WithDescription("Add a user or a user group as reviewer of a pull request in a Harness repository. Pass either reviewer_id or user_group_id."),
+			WithOutputSchema[reviewerResult](),
+			WithResponseShaping(),
+			// adding a reviewer changes nothing that exists already, and adding it twice is the same as once
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithString("repo_id",
+				mcp.Required(),
+				mcp.Description("The ID of the repository"),
+			),
+			mcp.WithNumber("pr_number",
+				mcp.Required(),
+				mcp.Description("The number of the pull request"),
+			),
+			withReviewerParams(),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			repoID, err := requiredParam[string](request, "repo_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			prNumberFloat, err := requiredParam[float64](request, "pr_number")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			prNumber := int(prNumberFloat)
+
+			reviewerID, userGroupID, err := fetchReviewer(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			if userGroupID != 0 {
+				data, err := client.PullRequests.AddUserGroupReviewer(ctx, scope, repoID, prNumber, userGroupID)
+				if err != nil {
+					return apiErrorResult(err, "add pull request user group reviewer", scope), nil
+				}
+				return structuredResult(reviewerResult{UserGroupReviewer: data}, "pull request reviewer")
+			}
+
+			data, err := client.PullRequests.AddReviewer(ctx, scope, repoID, prNumber, reviewerID)
+			if err != nil {
+				return apiErrorResult(err, "add pull request reviewer", scope), nil
+			}
+			return structuredResult(reviewerResult{Reviewer: data}, "pull request reviewer")
+		}
+}
+
+// RemovePullRequestReviewerTool creates a tool for removing a user or a user group from the reviewers of a pull request
+func RemovePullRequestReviewerTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("remove_pull_request_reviewer",
+			mcp.WithDescription("Remove a user or a user group from the reviewers of a pull request in a Harness repository, along with their review decision. Pass either reviewer_id or user_group_id."),
+			mcp.WithString("repo_id",
+				mcp.Required(),
+				mcp.Description("The ID of the repository"),
+			),
+			mcp.WithNumber("pr_number",
+				mcp.Required(),
+				mcp.Description("The number of the pull request"),
+			),
+			withReviewerParams(),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			repoID, err := requiredParam[string](request, "repo_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			prNumberFloat, err := requiredParam[float64](request, "pr_number")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			prNumber := int(prNumberFloat)
+
+			reviewerID, userGroupID, err := fetchReviewer(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			if userGroupID != 0 {
+				if err := client.PullRequests.RemoveUserGroupReviewer(ctx, scope, repoID, prNumber, userGroupID); err != nil {
+					return apiErrorResult(err, "remove pull request user group reviewer", scope), nil
+				}
+				return mcp.NewToolResultText(fmt.Sprintf("User group %d removed from the reviewers of pull request %d", userGroupID, prNumber)), nil
+			}
+
+			if err := client.PullRequests.RemoveReviewer(ctx, scope, repoID, prNumber, reviewerID); err != nil {
+				return apiErrorResult(err, "remove pull request reviewer", scope), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("User %d removed from the reviewers of pull request %d", reviewerID, prNumber)), nil
+		}
+}
+
+// SubmitPullRequestReviewTool creates a tool for approving a pull request or requesting changes
+func SubmitPullRequestReviewTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("submit_pull_request_review",
+			mcp.WithDescription("Submit a review decision on a pull request in a Harness repository: approve it, or request changes. The decision is made on the current source commit of the pull request, unless commit_sha is passed. Explain the decision with create_pull_request_comment."),
+			WithOutputSchema[reviewResult](),
+			WithResponseShaping(),
+			// a decision replaces the previous one of the reviewer, and submitting it twice is the same as once
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithString("repo_id",
+				mcp.Required(),
+				mcp.Description("The ID of the repository"),
+			),
+			mcp.WithNumber("pr_number",
+				mcp.Required(),
+				mcp.Description("The number of the pull request"),
+			),
+			mcp.WithString("decision",
+				mcp.Required(),
+				mcp.Description("The review decision: approved, or changereq to request changes"),
+				mcp.Enum(dto.ReviewDecisionApproved, dto.ReviewDecisionChangeReq),
+			),
+			mcp.WithString("commit_sha",
+				mcp.Description("Optional source commit the decision is made on, e.g. the one that was reviewed. Defaults to the current source commit"),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			repoID, err := requiredParam[string](request, "repo_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			prNumberFloat, err := requiredParam[float64](request, "pr_number")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			prNumber := int(prNumberFloat)
+
+			decision, err := requiredParam[string](request, "decision")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			switch decision {
+			case dto.ReviewDecisionApproved, dto.ReviewDecisionChangeReq:
+			default:
+				return mcp.NewToolResultError(fmt.Sprintf("invalid decision: %s, must be %s or %s", decision, dto.ReviewDecisionApproved, dto.ReviewDecisionChangeReq)), nil
+			}
+
+			commitSha, err := OptionalParam[string](request, "commit_sha")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			if commitSha == "" {
+				pr, err := client.PullRequests.Get(ctx, scope, repoID, prNumber)
+				if err != nil {
+					return apiErrorResult(err, "get pull request", scope), nil
+				}
+				commitSha = pr.SourceSha
+			}
+
+			review := &dto.SubmitPullRequestReview{CommitSha: commitSha, Decision: decision}
+			if err := client.PullRequests.SubmitReview(ctx, scope, repoID, prNumber, review); err != nil {
+				return apiErrorResult(err, "submit pull request review", scope), nil
+			}
+
+			return structuredResult(reviewResult{Decision: decision, CommitSha: commitSha}, "pull request review")
+		}
+}
+
+// withReviewerParams adds the parameters identifying a reviewer, a user or a user group.
+func withReviewerParams() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithNumber("reviewer_id",
+			mcp.Description("The ID of the user, e.g. the id of a reviewer listed by list_pull_request_reviewers or of the author of a comment"),
+		)(tool)
+		mcp.WithNumber("user_group_id",
+			mcp.Description("The ID of the user group"),
+		)(tool)
+	}
+}
+
+// fetchReviewer fetches the reviewer from the MCP request, either a user or a user group.
+func fetchReviewer(request mcp.CallToolRequest) (reviewerID, userGroupID int, err error) {
+	reviewerID, err = OptionalIntParam(request, "reviewer_id")
+	if err != nil {
+		return 0, 0, err
+	}
+	userGroupID, err = OptionalIntParam(request, "user_group_id")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if (reviewerID == 0) == (userGroupID == 0) {
+		return 0, 0, fmt.Errorf("either reviewer_id or user_group_id is required")
+	}
+	return reviewerID, userGroupID, nil
+}
@@ -0,0 +1,241 @@
+package harness
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/harness/harness-mcp/client"
+	"github.com/harness/harness-mcp/client/dto"
+	"github.com/harness/harness-mcp/cmd/harness-mcp-server/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// UpdatePullRequestTool creates a tool for updating the title, description or target branch of a pull request
+func UpdatePullRequestTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("update_pull_request",
+			mcp.WithDescription("Update the title, description or target branch of a pull request in a Harness repository. Only the fields that are passed are changed."),
+			WithOutputSchema[dto.PullRequest](),
+			WithResponseShaping(),
+			// the previous title and description are overwritten, but updating twice is the same as once
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithString("repo_id",
+				mcp.Required(),
+				mcp.Description("The ID of the repository"),
+			),
+			mcp.WithNumber("pr_number",
+				mcp.Required(),
+				mcp.Description("The number of the pull request"),
+			),
+			mcp.WithString("title",
+				mcp.Description("Optional new title of the pull request"),
+			),
+			mcp.WithString("description",
+				mcp.Description("Optional new description of the pull request. An empty description clears it"),
+			),
+			mcp.WithString("target_branch",
+				mcp.Description("Optional new target branch of the pull request"),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			repoID, err := requiredParam[string](request, "repo_id")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			prNumberFloat, err := requiredParam[float64](request, "pr_number")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			prNumber := int(prNumberFloat)
+
+			update := &dto.UpdatePullRequest{}
+
+			title, ok, err := OptionalParamOK[string](request, "title")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if ok {
+				if strings.TrimSpace(title) == "" {
+					return mcp.NewToolResultError("title must not be empty"), nil
+				}
+				update.Title = &title
+			}
+
+			description, ok, err := OptionalParamOK[string](request, "description")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if ok {
+				update.Description = &description
+			}
+
+			targetBranch, ok, err := OptionalParamOK[string](request, "target_branch")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if ok {
+				if targetBranch == "" {
+					return mcp.NewToolResultError("target_branch must not be empty"), nil
+				}
+				update.TargetBranch = &targetBranch
+			}
+
+			if update.Title == nil && update.Description == nil && update.TargetBranch == nil {
+				return mcp.NewToolResultError("one of title, description or target_branch must be provided"), nil
+			}
+
+			scope, err := fetchScope(config, request, true)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			data, err := client.PullRequests.Update(ctx, scope, repoID, prNumber, update)
+			if err != nil {
+				return apiErrorResult(err, "update pull request", scope), nil
+			}
+
+			return structuredResult(data, "pull request")
+		}
+}
+
+// ClosePullRequestTool creates a tool for closing a pull request without merging it
+func ClosePullRequestTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("close_pull_request",
+			mcp.WithDescription("Close a pull request in a Harness repository without merging it. It can be reopened with reopen_pull_request."),
+			WithOutputSchema[dto.PullRequest](),
+			WithResponseShaping(),
+			// closing can be undone by reopening, and closing twice is the same as once
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithString("repo_id",
+				mcp.Required(),
+				mcp.Description("The ID of the repository"),
+			),
+			mcp.WithNumber("pr_number",
+				mcp.Required(),
+				mcp.Description("The number of the pull request"),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return changePullRequestState(ctx, config, client, request, dto.PullRequestStateClosed, nil)
+		}
+}
+
+// ReopenPullRequestTool creates a tool for reopening a closed pull request
+func ReopenPullRequestTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("reopen_pull_request",
+			mcp.WithDescription("Reopen a closed pull request in a Harness repository. Merged pull requests cannot be reopened."),
+			WithOutputSchema[dto.PullRequest](),
+			WithResponseShaping(),
+			// reopening can be undone by closing, and reopening twice is the same as once
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithString("repo_id",
+				mcp.Required(),
+				mcp.Description("The ID of the repository"),
+			),
+			mcp.WithNumber("pr_number",
+				mcp.Required(),
+				mcp.Description("The number of the pull request"),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return changePullRequestState(ctx, config, client, request, dto.PullRequestStateOpen, nil)
+		}
+}
+
+// SetPullRequestDraftTool creates a tool for marking a pull request as ready for review, or as a draft
+func SetPullRequestDraftTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool("set_pull_request_draft",
+			mcp.WithDescription("Mark a draft pull request in a Harness repository as ready for review, or mark an open pull request as a draft again."),
+			WithOutputSchema[dto.PullRequest](),
+			WithResponseShaping(),
+			// the draft status can be switched back, and setting it twice is the same as once
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithString("repo_id",
+				mcp.Required(),
+				mcp.Description("The ID of the repository"),
+			),
+			mcp.WithNumber("pr_number",
+				mcp.Required(),
+				mcp.Description("The number of the pull request"),
+			),
+			mcp.WithBoolean("is_draft",
+				mcp.Required(),
+				mcp.Description("Whether the pull request is a draft. Set to false to mark it as ready for review"),
+			),
+			WithScope(config, true),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			// requiredParam rejects false, which marks the pull request as ready
+			isDraft, ok, err := OptionalParamOK[bool](request, "is_draft")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if !ok {
+				return mcp.NewToolResultError("missing required parameter: is_draft"), nil
+			}
+
+			return changePullRequestState(ctx, config, client, request, "", &isDraft)
+		}
+}
+
+// changePullRequestState moves a pull request to a state and, if isDraft is set, changes whether it is a draft.
+// An empty state keeps the current one. Harness sets the state and the draft status together, so the one that
+// is not changed is taken from the pull request. A pull request that is already as asked is returned unchanged.
+func changePullRequestState(ctx context.Context, config *config.Config, client *client.Client, request mcp.CallToolRequest, state string, isDraft *bool) (*mcp.CallToolResult, error) {
+	repoID, err := requiredParam[string](request, "repo_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	prNumberFloat, err := requiredParam[float64](request, "pr_number")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	prNumber := int(prNumberFloat)
+
+	scope, err := fetchScope(config, request, true)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	pr, err := client.PullRequests.Get(ctx, scope, repoID, prNumber)
+	if err != nil {
+		return apiErrorResult(err, "get pull request", scope), nil
+	}
+	if strings.EqualFold(pr.State, dto.PullRequestStateMerged) {
+		return mcp.NewToolResultError(fmt.Sprintf("pull request %d is merged, its state cannot be changed", prNumber)), nil
+	}
+
+	change := &dto.PullRequestState{
+		State:   strings.ToLower(pr.State),
+		IsDraft: pr.IsDraft,
+	}
+	if state != "" {
+		change.State = state
+	}
+	if isDraft != nil {
+		if *isDraft && change.State != dto.PullRequestStateOpen {
+			return mcp.NewToolResultError(fmt.Sprintf("pull request %d is %s, only open pull requests can be marked as draft", prNumber, change.State)), nil
+		}
+		change.IsDraft = *isDraft
+	}
+
+	if strings.EqualFold(pr.State, change.State) && pr.IsDraft == change.IsDraft {
+		return structuredResult(pr, "pull request")
+	}
+
+	data, err := client.PullRequests.UpdateState(ctx, scope, repoID, prNumber, change)
+	if err != nil {
+		return apiErrorResult(err, "update pull request state", scope), nil
+	}
+
+	return structuredResult(data, "pull request")
+}
@@ -0,0 +1,168 @@
+package harness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"github.com/harness/harness-mcp/pkg/redact"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// redactionsMetaKey is the key of the number of redactions in the _meta of tool results
+const redactionsMetaKey = "redactions"
+
+// RedactionMiddleware redacts secrets from the results of every tool before they are returned to the client,
+// since pipeline YAML, API responses and logs can contain tokens, passwords or connection strings.
+// The number of redactions is reported in the _meta of the result, and in a note the model can read.
+func RedactionMiddleware(redactor *redact.Redactor) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil || result == nil {
+				return result, err
+			}
+
+			count := redactResult(redactor, result)
+			if count == 0 {
+				return result, nil
+			}
+
+			slog.Debug("Redacted secrets from tool result", "tool", request.Params.Name, "redactions", count)
+			if result.Meta == nil {
+				result.Meta = &mcp.Meta{}
+			}
+			if result.Meta.AdditionalFields == nil {
+				result.Meta.AdditionalFields = make(map[string]any)
+			}
+			result.Meta.AdditionalFields[redactionsMetaKey] = count
+			result.Content = append(result.Content, mcp.NewTextContent(
+				fmt.Sprintf("Note: %d value(s) that looked like secrets were replaced with [REDACTED:<kind>] placeholders.", count)))
+
+			return result, nil
+		}
+	}
+}
+
+// RedactionResourceMiddleware redacts secrets from the contents of every resource read,
+// as resources expose the same pipeline YAML, executions and files as the tools do.
+func RedactionResourceMiddleware(redactor *redact.Redactor) server.ResourceHandlerMiddleware {
+	return func(next server.ResourceHandlerFunc) server.ResourceHandlerFunc {
+		return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			contents, err := next(ctx, request)
+			if err != nil {
+				return contents, err
+			}
+
+			count := 0
+			for i, content := range contents {
+				if text, ok := content.(mcp.TextResourceContents); ok {
+					var n int
+					text.Text, n = redactor.Redact(text.Text)
+					contents[i] = text
+					count += n
+				}
+			}
+			if count > 0 {
+				slog.Debug("Redacted secrets from resource", "uri", request.Params.URI, "redactions", count)
+			}
+
+			return contents, nil
+		}
+	}
+}
+
+// redactPrompt redacts secrets from the messages of a prompt, which embed the same data as the tools return.
+// mcp-go has no middleware for prompts, so RegisterPrompts wraps each handler.
+func redactPrompt(redactor *redact.Redactor, next server.PromptHandlerFunc) server.PromptHandlerFunc {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		result, err := next(ctx, request)
+		if err != nil || result == nil {
+			return result, err
+		}
+
+		count := 0
+		for i, message := range result.Messages {
+			switch c := message.Content.(type) {
+			case mcp.TextContent:
+				var n int
+				c.Text, n = redactor.Redact(c.Text)
+				result.Messages[i].Content = c
+				count += n
+			case mcp.EmbeddedResource:
+				if text, ok := c.Resource.(mcp.TextResourceContents); ok {
+					var n int
+					text.Text, n = redactor.Redact(text.Text)
+					c.Resource = text
+					result.Messages[i].Content = c
+					count += n
+				}
+			}
+		}
+		if count > 0 {
+			slog.Debug("Redacted secrets from prompt", "prompt", request.Params.Name, "redactions", count)
+		}
+
+		return result, nil
+	}
+}
+
+// redactResult redacts the text and structured content of a tool result in place and returns the number of redactions.
+func redactResult(redactor *redact.Redactor, result *mcp.CallToolResult) int {
+	count := 0
+	for i, content := range result.Content {
+		switch c := content.(type) {
+		case mcp.TextContent:
+			var n int
+			c.Text, n = redactor.Redact(c.Text)
+			result.Content[i] = c
+			count += n
+		case mcp.EmbeddedResource:
+			if text, ok := c.Resource.(mcp.TextResourceContents); ok {
+				var n int
+				text.Text, n = redactor.Redact(text.Text)
+				c.Resource = text
+				result.Content[i] = c
+				count += n
+			}
+		}
+	}
+
+	if result.StructuredContent != nil {
+		structured, n, err := redactJSON(redactor, result.StructuredContent)
+		if err != nil {
+			// structured content that cannot be checked is dropped rather than risking a leak,
+			// the text content carries the same information
+			slog.Warn("Failed to redact structured content", "error", err)
+			result.StructuredContent = nil
+		} else {
+			result.StructuredContent = structured
+			count += n
+		}
+	}
+
+	return count
+}
+
+// redactJSON redacts a value through its JSON encoding. The redacted value has the type of the value,
+// which the shaping of results relies on.
+func redactJSON(redactor *redact.Redactor, value any) (any, int, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	redacted, n := redactor.Redact(string(data))
+	if n == 0 {
+		return value, 0, nil
+	}
+
+	out := reflect.New(reflect.TypeOf(value))
+	if err := json.Unmarshal([]byte(redacted), out.Interface()); err != nil {
+		return nil, 0, err
+	}
+	return out.Elem().Interface(), n, nil
+}
@@ -2,8 +2,6 @@ package harness
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
 	"github.com/harness/harness-mcp/client"
 	"github.com/harness/harness-mcp/client/dto"
@@ -16,6 +14,8 @@ import (
 func GetRepositoryTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_repository",
 			mcp.WithDescription("Get details of a specific repository in Harness."),
+			WithOutputSchema[dto.Repository](),
+			WithResponseShaping(),
 			mcp.WithString("repo_identifier",
 				mcp.Required(),
 				mcp.Description("The identifier of the repository"),
@@ -35,15 +35,10 @@ func GetRepositoryTool(config *config.Config, client *client.Client) (tool mcp.T
 
 			data, err := client.Repositories.Get(ctx, scope, repoIdentifier)
 			if err != nil {
-				return nil, fmt.Errorf("failed to get repository: %w", err)
+				return apiErrorResult(err, "get repository", scope), nil
 			}
 
-			r, err := json.Marshal(data)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal repository: %w", err)
-			}
-
-			return mcp.NewToolResultText(string(r)), nil
+			return structuredResult(data, "repository")
 		}
 }
 
@@ -51,6 +46,8 @@ func GetRepositoryTool(config *config.Config, client *client.Client) (tool mcp.T
 func ListRepositoriesTool(config *config.Config, client *client.Client) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("list_repositories",
 			mcp.WithDescription("List repositories in Harness."),
+			WithListOutputSchema[*dto.Repository](),
+			WithResponseShaping(),
 			mcp.WithString("query",
 				mcp.Description("Optional search term to filter repositories"),
 			),
@@ -123,14 +120,9 @@ func ListRepositoriesTool(config *config.Config, client *client.Client) (tool mc
 
 			data, err := client.Repositories.List(ctx, scope, opts)
 			if err != nil {
-				return nil, fmt.Errorf("failed to list repositories: %w", err)
-			}
-
-			r, err := json.Marshal(data)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal repository list: %w", err)
+				return apiErrorResult(err, "list repositories", scope), nil
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return structuredListResult(data, "repository list")
 		}
 }
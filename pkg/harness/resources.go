@@ -0,0 +1,259 @@
+package harness
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/harness/harness-mcp/client"
+	"github.com/harness/harness-mcp/client/dto"
+	"github.com/harness/harness-mcp/cmd/harness-mcp-server/config"
+	"github.com/harness/harness-mcp/pkg/toolsets"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// URI templates of the resources, org and project select the scope within the configured account
+const (
+	pipelineResourceTemplate    = "harness://{org}/{project}/pipelines/{pipeline_id}"
+	executionResourceTemplate   = "harness://{org}/{project}/executions/{plan_execution_id}"
+	pullRequestResourceTemplate = "harness://{org}/{project}/repos/{repo}/pullreq/{number}"
+	fileResourceTemplate        = "harness://{org}/{project}/repos/{repo}/files/{ref}/{+path}"
+)
+
+// RegisterResources registers the resource templates of the enabled toolsets with the server,
+// so that clients can attach Harness entities as context without calling tools.
+func RegisterResources(s *server.MCPServer, tsg *toolsets.ToolsetGroup, client *client.Client, config *config.Config) {
+	for _, name := range toolsetNames(tsg) {
+		if tsg.IsEnabled(name) {
+			RegisterToolsetResources(s, name, client, config)
+		}
+	}
+}
+
+// RegisterToolsetResources registers the resource templates of a toolset, e.g. when it is enabled at runtime.
+func RegisterToolsetResources(s *server.MCPServer, name string, client *client.Client, config *config.Config) {
+	switch name {
+	case "pipelines":
+		s.AddResourceTemplate(PipelineResource(config, client))
+		s.AddResourceTemplate(ExecutionResource(config, client))
+	case "pullrequests":
+		s.AddResourceTemplate(PullRequestResource(config, client))
+	case "repositories":
+		s.AddResourceTemplate(FileResource(config, client))
+	}
+}
+
+// PipelineResource creates a resource template for the YAML of a pipeline
+func PipelineResource(config *config.Config, client *client.Client) (mcp.ResourceTemplate, server.ResourceTemplateHandlerFunc) {
+	return mcp.NewResourceTemplate(pipelineResourceTemplate, "Harness pipeline",
+			mcp.WithTemplateDescription("The YAML of a pipeline in Harness"),
+			mcp.WithTemplateMIMEType("application/yaml"),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			scope, err := resourceScope(config, request)
+			if err != nil {
+				return nil, err
+			}
+			pipelineID, err := resourceArgument(request, "pipeline_id")
+			if err != nil {
+				return nil, err
+			}
+
+			data, err := client.Pipelines.Get(ctx, scope, pipelineID)
+			if err != nil {
+				return nil, errors.New(describeAPIError(err, "get pipeline", scope))
+			}
+
+			return []mcp.ResourceContents{mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "application/yaml",
+				Text:     data.Data.YamlPipeline,
+			}}, nil
+		}
+}
+
+// ExecutionResource creates a resource template for a pipeline execution with its stages and steps
+func ExecutionResource(config *config.Config, client *client.Client) (mcp.ResourceTemplate, server.ResourceTemplateHandlerFunc) {
+	return mcp.NewResourceTemplate(executionResourceTemplate, "Harness pipeline execution",
+			mcp.WithTemplateDescription("A pipeline execution in Harness with the status, timings and failures of its stages and steps"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			scope, err := resourceScope(config, request)
+			if err != nil {
+				return nil, err
+			}
+			planExecutionID, err := resourceArgument(request, "plan_execution_id")
+			if err != nil {
+				return nil, err
+			}
+
+			data, err := client.Pipelines.GetExecutionGraph(ctx, scope, planExecutionID)
+			if err != nil {
+				return nil, errors.New(describeAPIError(err, "get execution graph", scope))
+			}
+
+			return jsonResourceContents(request.Params.URI, buildExecutionTree(data.Data))
+		}
+}
+
+// PullRequestResource creates a resource template for a pull request
+func PullRequestResource(config *config.Config, client *client.Client) (mcp.ResourceTemplate, server.ResourceTemplateHandlerFunc) {
+	return mcp.NewResourceTemplate(pullRequestResourceTemplate, "Harness pull request",
+			mcp.WithTemplateDescription("A pull request of a Harness Code repository"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			scope, err := resourceScope(config, request)
+			if err != nil {
+				return nil, err
+			}
+			repoID, err := resourceArgument(request, "repo")
+			if err != nil {
+				return nil, err
+			}
+			number, err := resourceArgument(request, "number")
+			if err != nil {
+				return nil, err
+			}
+			prNumber, err := strconv.Atoi(number)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pull request number: %s", number)
+			}
+
+			data, err := client.PullRequests.Get(ctx, scope, repoID, prNumber)
+			if err != nil {
+				return nil, errors.New(describeAPIError(err, "get pull request", scope))
+			}
+
+			return jsonResourceContents(request.Params.URI, data)
+		}
+}
+
+// FileResource creates a resource template for a file of a repository at a git ref
+func FileResource(config *config.Config, client *client.Client) (mcp.ResourceTemplate, server.ResourceTemplateHandlerFunc) {
+	return mcp.NewResourceTemplate(fileResourceTemplate, "Harness repository file",
+			mcp.WithTemplateDescription("A file of a Harness Code repository at a branch, tag or commit. Refs containing slashes must be percent-encoded. Directories are listed one entry per line."),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			scope, err := resourceScope(config, request)
+			if err != nil {
+				return nil, err
+			}
+			repoID, err := resourceArgument(request, "repo")
+			if err != nil {
+				return nil, err
+			}
+			ref, err := resourceArgument(request, "ref")
+			if err != nil {
+				return nil, err
+			}
+			filePath, err := resourceArgument(request, "path")
+			if err != nil {
+				return nil, err
+			}
+
+			content, err := client.Repositories.GetContent(ctx, scope, repoID, ref, filePath)
+			if err != nil {
+				return nil, errors.New(describeAPIError(err, "get file", scope))
+			}
+
+			return fileResourceContents(request.Params.URI, content)
+		}
+}
+
+// fileResourceContents converts repository content to resource contents, as text if it is valid UTF-8.
+func fileResourceContents(uri string, content *dto.RepositoryContent) ([]mcp.ResourceContents, error) {
+	if content.Type == "dir" {
+		var b strings.Builder
+		for _, entry := range content.Content.Entries {
+			name := entry.Name
+			if entry.Type == "dir" {
+				name += "/"
+			}
+			b.WriteString(name + "\n")
+		}
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: "text/plain", Text: b.String()}}, nil
+	}
+
+	data := []byte(content.Content.Data)
+	if content.Content.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(content.Content.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode file content: %w", err)
+		}
+		data = decoded
+	}
+
+	mimeType := mime.TypeByExtension(path.Ext(content.Path))
+	if !utf8.Valid(data) {
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+		return []mcp.ResourceContents{mcp.BlobResourceContents{
+			URI:      uri,
+			MIMEType: mimeType,
+			Blob:     base64.StdEncoding.EncodeToString(data),
+		}}, nil
+	}
+
+	if mimeType == "" {
+		mimeType = "text/plain"
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: string(data)}}, nil
+}
+
+// jsonResourceContents marshals v as the JSON content of a resource.
+func jsonResourceContents(uri string, v any) ([]mcp.ResourceContents, error) {
+	r, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resource: %w", err)
+	}
+	return []mcp.ResourceContents{mcp.TextResourceContents{
+		URI:      uri,
+		MIMEType: "application/json",
+		Text:     string(r),
+	}}, nil
+}
+
+// resourceScope builds the scope of a resource from the configured account and the org and project of its URI.
+func resourceScope(config *config.Config, request mcp.ReadResourceRequest) (dto.Scope, error) {
+	if config.AccountID == "" {
+		return dto.Scope{}, fmt.Errorf("account ID is required")
+	}
+	org, err := resourceArgument(request, "org")
+	if err != nil {
+		return dto.Scope{}, err
+	}
+	project, err := resourceArgument(request, "project")
+	if err != nil {
+		return dto.Scope{}, err
+	}
+	return dto.Scope{AccountID: config.AccountID, OrgID: org, ProjectID: project}, nil
+}
+
+// resourceArgument returns a variable of the URI template matched by the request.
+func resourceArgument(request mcp.ReadResourceRequest, name string) (string, error) {
+	var value string
+	switch v := request.Params.Arguments[name].(type) {
+	case string:
+		value = v
+	case []string:
+		// variables matched by the URI template are lists of values
+		if len(v) > 0 {
+			value = v[0]
+		}
+	}
+	if value == "" {
+		return "", fmt.Errorf("missing %s in resource URI %s", name, request.Params.URI)
+	}
+	return value, nil
+}
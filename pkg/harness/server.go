@@ -1,7 +1,9 @@
 package harness
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -13,7 +15,12 @@ func NewServer(version string, opts ...server.ServerOption) *server.MCPServer {
 	defaultOpts := []server.ServerOption{
 		server.WithToolCapabilities(true),
 		server.WithResourceCapabilities(true, true),
+		server.WithPromptCapabilities(true),
 		server.WithLogging(),
+		// recovery is the outermost tool middleware, so that panics in any of the others are recovered too
+		server.WithRecovery(),
+		// shaping comes next, so that it shapes results after the other middlewares, e.g. redaction, are done with them
+		server.WithToolHandlerMiddleware(ShapingMiddleware()),
 	}
 	opts = append(defaultOpts, opts...)
 
@@ -26,13 +33,34 @@ func NewServer(version string, opts ...server.ServerOption) *server.MCPServer {
 	return s
 }
 
+// sendProgress sends a progress notification for a long running tool call,
+// if the client asked for progress by passing a progress token.
+func sendProgress(ctx context.Context, r mcp.CallToolRequest, progress float64, message string) {
+	if r.Params.Meta == nil || r.Params.Meta.ProgressToken == nil {
+		return
+	}
+	s := server.ServerFromContext(ctx)
+	if s == nil {
+		return
+	}
+
+	err := s.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+		"progressToken": r.Params.Meta.ProgressToken,
+		"progress":      progress,
+		"message":       message,
+	})
+	if err != nil {
+		slog.Debug("Failed to send progress notification", "error", err)
+	}
+}
+
 // Helper functions for parameter handling
 
 // OptionalParamOK is a helper function that can be used to fetch a requested parameter from the request.
 // It returns the value, a boolean indicating if the parameter was present, and an error if the type is wrong.
 func OptionalParamOK[T any](r mcp.CallToolRequest, p string) (value T, ok bool, err error) {
 	// Check if the parameter is present in the request
-	val, exists := r.Params.Arguments[p]
+	val, exists := r.GetArguments()[p]
 	if !exists {
 		// Not present, return zero value, false, no error
 		return
@@ -57,20 +85,20 @@ func requiredParam[T comparable](r mcp.CallToolRequest, p string) (T, error) {
 	var zero T
 
 	// Check if the parameter is present in the request
-	if _, ok := r.Params.Arguments[p]; !ok {
+	if _, ok := r.GetArguments()[p]; !ok {
 		return zero, fmt.Errorf("missing required parameter: %s", p)
 	}
 
 	// Check if the parameter is of the expected type
-	if _, ok := r.Params.Arguments[p].(T); !ok {
+	if _, ok := r.GetArguments()[p].(T); !ok {
 		return zero, fmt.Errorf("parameter %s is not of type %T", p, zero)
 	}
 
-	if r.Params.Arguments[p].(T) == zero {
+	if r.GetArguments()[p].(T) == zero {
 		return zero, fmt.Errorf("missing required parameter: %s", p)
 	}
 
-	return r.Params.Arguments[p].(T), nil
+	return r.GetArguments()[p].(T), nil
 }
 
 // OptionalParam is a helper function that can be used to fetch an optional parameter from the request.
@@ -78,16 +106,16 @@ func OptionalParam[T any](r mcp.CallToolRequest, p string) (T, error) {
 	var zero T
 
 	// Check if the parameter is present in the request
-	if _, ok := r.Params.Arguments[p]; !ok {
+	if _, ok := r.GetArguments()[p]; !ok {
 		return zero, nil
 	}
 
 	// Check if the parameter is of the expected type
-	if _, ok := r.Params.Arguments[p].(T); !ok {
-		return zero, fmt.Errorf("parameter %s is not of type %T, is %T", p, zero, r.Params.Arguments[p])
+	if _, ok := r.GetArguments()[p].(T); !ok {
+		return zero, fmt.Errorf("parameter %s is not of type %T, is %T", p, zero, r.GetArguments()[p])
 	}
 
-	return r.Params.Arguments[p].(T), nil
+	return r.GetArguments()[p].(T), nil
 }
 
 // OptionalIntParam is a helper function that can be used to fetch a requested parameter from the request.
@@ -118,11 +146,11 @@ func OptionalIntParamWithDefault(r mcp.CallToolRequest, p string, d int) (int, e
 // OptionalStringArrayParam is a helper function that can be used to fetch an optional string array parameter.
 func OptionalStringArrayParam(r mcp.CallToolRequest, p string) ([]string, error) {
 	// Check if the parameter is present in the request
-	if _, ok := r.Params.Arguments[p]; !ok {
+	if _, ok := r.GetArguments()[p]; !ok {
 		return []string{}, nil
 	}
 
-	switch v := r.Params.Arguments[p].(type) {
+	switch v := r.GetArguments()[p].(type) {
 	case nil:
 		return []string{}, nil
 	case []string:
@@ -138,6 +166,6 @@ func OptionalStringArrayParam(r mcp.CallToolRequest, p string) ([]string, error)
 		}
 		return strSlice, nil
 	default:
-		return []string{}, fmt.Errorf("parameter %s could not be coerced to []string, is %T", p, r.Params.Arguments[p])
+		return []string{}, fmt.Errorf("parameter %s could not be coerced to []string, is %T", p, r.GetArguments()[p])
 	}
 }
@@ -0,0 +1,33 @@
+package harness
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// panickingList is a list result that panics while it is shaped
+type panickingList struct{}
+
+func (panickingList) listItems() any {
+	panic("shaping failed")
+}
+
+func TestNewServerRecoversFromShapingPanics(t *testing.T) {
+	s := NewServer("test")
+	s.AddTool(mcp.NewTool("broken"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultStructured(panickingList{}, "[]"), nil
+	})
+
+	response := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"broken"}}`))
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "panic recovered in broken tool handler") {
+		t.Errorf("response = %s, want a recovered panic", data)
+	}
+}
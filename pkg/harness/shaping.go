@@ -0,0 +1,859 @@
+package harness
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/harness/harness-mcp/client/dto"
+	"github.com/harness/harness-mcp/pkg/textutil"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// views of tool results
+	viewCompact = "compact"
+	viewFull    = "full"
+
+	// nextCursorMetaKey is the key of the continuation cursor in the _meta of truncated tool results
+	nextCursorMetaKey = "next_cursor"
+
+	// the smallest budget of a result, below which no useful part of it fits
+	minMaxChars = 500
+	// values shorter than this are not shortened to fit a budget
+	minShortenedSize = 64
+
+	shortenedMarker = "…[shortened]"
+)
+
+// compactViews lists the fields left out of the compact view of an entity type, which is the default view of
+// tool results. Fields are dot separated paths, see parseFieldPath. Empty values are left out of compact views as well.
+var compactViews = map[reflect.Type][]string{
+	reflect.TypeOf(dto.PipelineData{}): {
+		// the YAML with resolved templates is mostly a copy of the pipeline YAML
+		"resolvedTemplatesPipelineYaml",
+	},
+	reflect.TypeOf(dto.ListOutput[dto.PipelineListItem]{}): {
+		"data.pageable", "data.sort", "data.empty",
+		"data.content.filters", "data.content.executionSummaryInfo.numOfErrors", "data.content.executionSummaryInfo.deployments",
+	},
+	reflect.TypeOf(dto.ListOutput[dto.PipelineExecution]{}): {
+		"data.pageable", "data.sort", "data.empty",
+		"data.content.shouldUseSimplifiedBaseKey", "data.content.abortedBy.createdAt",
+	},
+	reflect.TypeOf(dto.PipelineExecution{}): {
+		"shouldUseSimplifiedBaseKey", "abortedBy.createdAt",
+	},
+	reflect.TypeOf(dto.PullRequest{}): {
+		"author.created", "author.updated", "author.type",
+		"merger.created", "merger.updated", "merger.type",
+		"labels.color", "labels.value_color", "labels.scope", "labels.id", "labels.value_id", "labels.value_count",
+		"source_repo_id", "target_repo_id",
+	},
+	reflect.TypeOf(dto.PullRequestActivity{}): {
+		"author.created", "author.updated", "author.type",
+		"resolver.created", "resolver.updated", "resolver.type",
+		"order", "sub_order",
+	},
+	reflect.TypeOf(pullRequestReviewers{}): {
+		"reviewers.reviewer.created", "reviewers.reviewer.updated", "reviewers.reviewer.type",
+		"reviewers.added_by.created", "reviewers.added_by.updated", "reviewers.added_by.type",
+		"user_groups.added_by.created", "user_groups.added_by.updated", "user_groups.added_by.type",
+		"user_groups.user_decisions.reviewer.created", "user_groups.user_decisions.reviewer.updated", "user_groups.user_decisions.reviewer.type",
+	},
+	reflect.TypeOf(dto.PullRequestChecksResponse{}): {
+		"checks.check.metadata", "checks.check.payload",
+		"checks.check.reported_by.created", "checks.check.reported_by.updated", "checks.check.reported_by.type",
+	},
+	reflect.TypeOf(dto.Repository{}): {
+		"created_by", "deleted", "fork_id", "parent_id", "importing", "size_updated", "state", "git_ssh_url",
+	},
+}
+
+// shapingOptions are the parameters shaping the result of a tool, see WithResponseShaping
+type shapingOptions struct {
+	fields   [][]string
+	view     string
+	maxChars int
+	cursor   *responseCursor
+}
+
+// responseCursor is where a truncated result continues: the offset of the next item of a list,
+// or of the next byte of a string, at a path of the result
+type responseCursor struct {
+	Path   []string `json:"path"`
+	Offset int      `json:"offset"`
+}
+
+// WithResponseShaping adds the parameters shaping the result of a tool: the fields to return, the view,
+// the maximum size of the result, and the cursor of a truncated result. They are applied by ShapingMiddleware.
+func WithResponseShaping() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithString("fields",
+			mcp.Description("Optional comma-separated fields of the result to return, as dot separated paths like data.content.name. Paths go through lists, and * matches any field. Returns all fields when set, regardless of view"),
+		)(tool)
+		mcp.WithString("view",
+			mcp.Description("Optional view of the result: compact leaves out empty values and rarely useful fields, full returns the result as is"),
+			mcp.Enum(viewCompact, viewFull),
+			mcp.DefaultString(viewCompact),
+		)(tool)
+		WithTextShaping()(tool)
+	}
+}
+
+// WithTextShaping adds the parameters truncating the text result of a tool: the maximum size of the result,
+// and the cursor of a truncated result. They are applied by ShapingMiddleware.
+func WithTextShaping() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		mcp.WithNumber("max_chars",
+			mcp.Description("Optional maximum number of characters of the result. Longer results are truncated, and come with a cursor to get the rest"),
+			mcp.Min(minMaxChars),
+		)(tool)
+		mcp.WithString("cursor",
+			mcp.Description("Optional cursor returned with a truncated result, to continue where it stopped. The other arguments must be the same as in the call that returned it"),
+		)(tool)
+	}
+}
+
+// ShapingMiddleware shapes the structured results of tools to spend fewer tokens: it selects the fields asked for,
+// or the compact view of the result, and truncates it to max_chars. Results without structured content are
+// truncated to max_chars as text. A truncated result continues with the cursor reported in its _meta and
+// in a note the model can read.
+func ShapingMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			// check the options before calling the tool, so that mistakes do not cost API calls
+			options, err := fetchShapingOptions(request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			result, err := next(ctx, request)
+			if err != nil || result == nil || result.IsError {
+				return result, err
+			}
+			if result.StructuredContent == nil {
+				return shapeTextResult(result, options)
+			}
+
+			return shapeResult(result, options)
+		}
+	}
+}
+
+// fetchShapingOptions fetches the parameters shaping the result from the MCP request.
+func fetchShapingOptions(request mcp.CallToolRequest) (shapingOptions, error) {
+	options := shapingOptions{view: viewCompact}
+
+	fields, err := OptionalParam[string](request, "fields")
+	if err != nil {
+		return options, err
+	}
+	for _, field := range splitAndTrim(fields, ",") {
+		path, err := parseFieldPath(field)
+		if err != nil {
+			return options, err
+		}
+		options.fields = append(options.fields, path)
+	}
+
+	view, err := OptionalParam[string](request, "view")
+	if err != nil {
+		return options, err
+	}
+	switch view {
+	case "":
+	case viewCompact, viewFull:
+		options.view = view
+	default:
+		return options, fmt.Errorf("invalid view: %s, must be %s or %s", view, viewCompact, viewFull)
+	}
+
+	options.maxChars, err = OptionalIntParam(request, "max_chars")
+	if err != nil {
+		return options, err
+	}
+	if options.maxChars != 0 && options.maxChars < minMaxChars {
+		return options, fmt.Errorf("max_chars must be at least %d", minMaxChars)
+	}
+
+	cursor, err := OptionalParam[string](request, "cursor")
+	if err != nil {
+		return options, err
+	}
+	if cursor != "" {
+		options.cursor, err = decodeCursor(cursor)
+		if err != nil {
+			return options, err
+		}
+	}
+
+	return options, nil
+}
+
+// parseFieldPath parses a dot separated path of fields. For JSONPath users, a leading $ and [] or [*] after
+// the name of a list are accepted and ignored.
+func parseFieldPath(field string) ([]string, error) {
+	field = strings.TrimPrefix(strings.TrimPrefix(field, "$"), ".")
+	if field == "" {
+		return nil, nil
+	}
+
+	path := strings.Split(field, ".")
+	for i, name := range path {
+		name = strings.TrimSuffix(strings.TrimSuffix(name, "[*]"), "[]")
+		if name == "" {
+			return nil, fmt.Errorf("invalid field: %s", field)
+		}
+		path[i] = name
+	}
+	return path, nil
+}
+
+// shapeResult shapes the structured content of a tool result, and replaces its JSON text with the shaped content.
+func shapeResult(result *mcp.CallToolResult, options shapingOptions) (*mcp.CallToolResult, error) {
+	// lists are shaped as the text shows them, without the object wrapping them in the structured content
+	value := result.StructuredContent
+	list, isList := value.(listResultValue)
+	if isList {
+		value = list.listItems()
+	}
+
+	doc, err := toJSONValue(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to shape result: %w", err)
+	}
+
+	switch {
+	case len(options.fields) > 0:
+		doc = selectFields(doc, newFieldTree(options.fields))
+	case options.view == viewCompact:
+		for _, field := range compactViews[entityType(value)] {
+			path, _ := parseFieldPath(field)
+			omitField(doc, path)
+		}
+		doc = omitEmpty(doc)
+	}
+
+	var cut *truncation
+	if options.cursor != nil {
+		doc, err = skipToCursor(doc, *options.cursor)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	if options.maxChars > 0 {
+		doc, cut = truncate(doc, options.maxChars, options.cursor)
+	}
+
+	text, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal shaped result: %w", err)
+	}
+	for i, content := range result.Content {
+		// the first text content is the JSON, the others are notes
+		if _, ok := content.(mcp.TextContent); ok {
+			result.Content[i] = mcp.NewTextContent(string(text))
+			break
+		}
+	}
+	if isList {
+		result.StructuredContent = map[string]any{"items": doc}
+	} else {
+		result.StructuredContent = doc
+	}
+
+	if cut != nil {
+		cut.annotate(result)
+	}
+	return result, nil
+}
+
+// shapeTextResult truncates the text of a tool result without structured content to max_chars bytes.
+// The cursor of a text result is a byte offset into its text, with an empty path.
+func shapeTextResult(result *mcp.CallToolResult, options shapingOptions) (*mcp.CallToolResult, error) {
+	if options.maxChars == 0 && options.cursor == nil {
+		return result, nil
+	}
+
+	for i, content := range result.Content {
+		// the first text content is the result, the others are notes
+		text, ok := content.(mcp.TextContent)
+		if !ok {
+			continue
+		}
+
+		rest, base := text.Text, 0
+		if options.cursor != nil {
+			skipped, err := skipToCursor(rest, *options.cursor)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			rest, base = skipped.(string), options.cursor.Offset
+		}
+
+		var cut *truncation
+		if options.maxChars > 0 && len(rest) > options.maxChars {
+			kept := textutil.RunePrefixLen(rest, options.maxChars)
+			cut = &truncation{maxChars: options.maxChars, unit: "bytes"}
+			cut.from, cut.to, cut.total = base, base+kept, base+len(rest)
+			cut.next = &responseCursor{Offset: base + kept}
+			rest = rest[:kept]
+		}
+
+		result.Content[i] = mcp.NewTextContent(rest)
+		if cut != nil {
+			cut.annotate(result)
+		}
+		return result, nil
+	}
+	return result, nil
+}
+
+// listResultValue is implemented by the structured content of tools returning a list
+type listResultValue interface {
+	listItems() any
+}
+
+func (r listResult[T]) listItems() any {
+	return r.Items
+}
+
+// entityType returns the type of the entities a value holds, the type of its items for lists.
+func entityType(value any) reflect.Type {
+	t := reflect.TypeOf(value)
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	return t
+}
+
+// toJSONValue converts a value to the maps, slices and scalars of its JSON encoding, keeping numbers as they are.
+func toJSONValue(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// fieldTree is a set of field paths, merged by their common prefixes. A nil tree selects a whole value.
+type fieldTree map[string]fieldTree
+
+func newFieldTree(paths [][]string) fieldTree {
+	tree := fieldTree{}
+	for _, path := range paths {
+		if len(path) == 0 {
+			// the whole result
+			return nil
+		}
+
+		node := tree
+		for i, name := range path {
+			child, ok := node[name]
+			if ok && child == nil {
+				// a shorter path already selects the whole field
+				break
+			}
+			if i == len(path)-1 {
+				node[name] = nil
+				break
+			}
+			if !ok {
+				child = fieldTree{}
+				node[name] = child
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+// selectFields returns the fields of a value selected by a tree. Lists are selected from item by item.
+func selectFields(value any, tree fieldTree) any {
+	if tree == nil {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		selected := make(map[string]any)
+		for name, field := range v {
+			node, ok := tree[name]
+			if !ok {
+				node, ok = tree["*"]
+			}
+			if ok {
+				if field = selectFields(field, node); field != nil {
+					selected[name] = field
+				}
+			}
+		}
+		return selected
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = selectFields(item, tree)
+		}
+		return items
+	default:
+		// the path goes past a scalar
+		return nil
+	}
+}
+
+// omitField removes a field from a value in place. Lists are removed from item by item.
+func omitField(value any, path []string) {
+	if len(path) == 0 {
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if len(path) == 1 {
+			delete(v, path[0])
+			return
+		}
+		omitField(v[path[0]], path[1:])
+	case []any:
+		for _, item := range v {
+			omitField(item, path)
+		}
+	}
+}
+
+// omitEmpty removes the null, empty string, empty object and empty list fields of objects.
+// Items of lists are kept, so that cursors count them.
+func omitEmpty(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for name, field := range v {
+			field = omitEmpty(field)
+			if isEmptyJSONValue(field) {
+				delete(v, name)
+			} else {
+				v[name] = field
+			}
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = omitEmpty(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isEmptyJSONValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// encodeCursor encodes a cursor as an opaque string.
+func encodeCursor(cursor responseCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes a cursor returned by encodeCursor.
+func decodeCursor(s string) (*responseCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", s)
+	}
+	var cursor responseCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Offset < 0 {
+		return nil, fmt.Errorf("invalid cursor: %s", s)
+	}
+	return &cursor, nil
+}
+
+// skipToCursor drops the part of the list or string at the path of a cursor that came before it.
+func skipToCursor(doc any, cursor responseCursor) (any, error) {
+	target, ok := valueAt(doc, cursor.Path)
+	if !ok {
+		return nil, fmt.Errorf("cursor does not match the result, the arguments must be the same as in the call that returned it")
+	}
+
+	switch v := target.(type) {
+	case []any:
+		if cursor.Offset > len(v) {
+			return nil, fmt.Errorf("cursor is past the end of %s", displayPath(cursor.Path))
+		}
+		return replaceValueAt(doc, cursor.Path, v[cursor.Offset:]), nil
+	case string:
+		if cursor.Offset > len(v) {
+			return nil, fmt.Errorf("cursor is past the end of %s", displayPath(cursor.Path))
+		}
+		return replaceValueAt(doc, cursor.Path, v[cursor.Offset:]), nil
+	default:
+		return nil, fmt.Errorf("cursor does not match the result, the arguments must be the same as in the call that returned it")
+	}
+}
+
+// truncation describes how a result was truncated to fit its budget
+type truncation struct {
+	maxChars int
+	// the list or string that was cut, from one item or byte to another of the total, and continues at next
+	path            []string
+	unit            string
+	from, to, total int
+	next            *responseCursor
+	// the paths of the values that were shortened
+	shortened []string
+}
+
+// annotate reports a truncation in the _meta of a result, and in a note the model can read.
+func (t *truncation) annotate(result *mcp.CallToolResult) {
+	if t.next != nil {
+		cursor := encodeCursor(*t.next)
+		if result.Meta == nil {
+			result.Meta = &mcp.Meta{}
+		}
+		if result.Meta.AdditionalFields == nil {
+			result.Meta.AdditionalFields = make(map[string]any)
+		}
+		result.Meta.AdditionalFields[nextCursorMetaKey] = cursor
+		result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf(
+			"Note: the result was truncated to fit max_chars=%d, it has %s %d to %d of the %d of %s. Call the tool again with the same arguments and cursor %q to get the rest.",
+			t.maxChars, t.unit, t.from+1, t.to, t.total, displayPath(t.path), cursor)))
+	}
+	if len(t.shortened) > 0 {
+		result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf(
+			"Note: long values were shortened to fit max_chars=%d: %s. Select them with fields to get them in full.",
+			t.maxChars, strings.Join(t.shortened, ", "))))
+	}
+}
+
+// truncate fits a result in a budget of characters. The largest list or string of the result, or the one the
+// cursor points to, is cut where the budget runs out, and continues at the returned cursor. If the rest of the
+// result does not fit either, its longest values are shortened without a cursor.
+func truncate(doc any, maxChars int, cursor *responseCursor) (any, *truncation) {
+	if jsonSize(doc) <= maxChars {
+		return doc, nil
+	}
+
+	t := &truncation{maxChars: maxChars}
+	var target any
+	var base int
+	found := false
+	if cursor != nil {
+		t.path, base = cursor.Path, cursor.Offset
+		target, found = valueAt(doc, t.path)
+	} else if candidates := truncationCandidates(doc); len(candidates) > 0 {
+		t.path, target, found = candidates[0].path, candidates[0].value, true
+	}
+	if found {
+		// leave at least half of the budget to the target, shortening the other values if needed
+		var empty any = []any{}
+		if _, ok := target.(string); ok {
+			empty = ""
+		}
+		doc = replaceValueAt(t.shortenOthers(replaceValueAt(doc, t.path, empty), maxChars/2), t.path, target)
+		doc = cutTarget(doc, target, base, t)
+	}
+	doc = t.shortenOthers(doc, maxChars)
+
+	if t.next == nil && len(t.shortened) == 0 {
+		return doc, nil
+	}
+	return doc, t
+}
+
+// shortenOthers shortens the longest values of a result other than the target until the result fits in size.
+// The sizes of the values are computed once, and updated as the values and the lists holding them are shortened.
+func (t *truncation) shortenOthers(doc any, size int) any {
+	docSize := jsonSize(doc)
+	if docSize <= size {
+		return doc
+	}
+
+	// the target and the values holding it are never shortened
+	var queue candidateQueue
+	sizes := make(map[string]int)
+	for _, c := range truncationCandidates(doc) {
+		if !isPathPrefix(c.path, t.path) {
+			queue = append(queue, c)
+			sizes[pathKey(c.path)] = c.size
+		}
+	}
+	heap.Init(&queue)
+
+	for docSize > size && queue.Len() > 0 {
+		c := heap.Pop(&queue).(truncationCandidate)
+		key := pathKey(c.path)
+		if current := sizes[key]; current != c.size {
+			// one of its items was shortened since it was queued
+			c.size = current
+			heap.Push(&queue, c)
+			continue
+		}
+		// values only get smaller, so a value that cannot be shortened now never can. Values of
+		// items cut from their list are gone.
+		value, ok := valueAt(doc, c.path)
+		if !ok || c.size < minShortenedSize || !shortenable(value) {
+			continue
+		}
+
+		shortened := shorten(value)
+		saved := c.size - jsonSize(shortened)
+		doc = replaceValueAt(doc, c.path, shortened)
+		docSize -= saved
+		for i := range c.path {
+			if prefix := pathKey(c.path[:i]); sizes[prefix] > 0 {
+				sizes[prefix] -= saved
+			}
+		}
+		c.value, c.size = shortened, c.size-saved
+		sizes[key] = c.size
+		heap.Push(&queue, c)
+
+		if path := displayPath(c.path); !slices.Contains(t.shortened, path) {
+			t.shortened = append(t.shortened, path)
+		}
+	}
+	return doc
+}
+
+// cutTarget cuts the list or string of a truncation to what fits in the budget, keeping at least one item
+// or character so that cursors always move forward. base is the offset the target starts at.
+func cutTarget(doc, target any, base int, t *truncation) any {
+	switch v := target.(type) {
+	case []any:
+		budget := t.maxChars - jsonSize(replaceValueAt(doc, t.path, []any{}))
+		kept, size := 0, 0
+		for kept < len(v) {
+			size += jsonSize(v[kept]) + 1
+			if size > budget && kept > 0 {
+				break
+			}
+			kept++
+		}
+		if kept < len(v) {
+			t.unit, t.from, t.to, t.total = "items", base, base+kept, base+len(v)
+			t.next = &responseCursor{Path: t.path, Offset: base + kept}
+		}
+		return replaceValueAt(doc, t.path, v[:kept])
+	case string:
+		budget := t.maxChars - jsonSize(replaceValueAt(doc, t.path, ""))
+		// the longest prefix whose JSON encoding fits, cut at a character boundary
+		kept := sort.Search(len(v)+1, func(n int) bool {
+			return jsonSize(v[:n]) > budget
+		}) - 1
+		for kept > 0 && kept < len(v) && !utf8.RuneStart(v[kept]) {
+			kept--
+		}
+		if kept <= 0 {
+			_, kept = utf8.DecodeRuneInString(v)
+		}
+		if kept < len(v) {
+			t.unit, t.from, t.to, t.total = "bytes", base, base+kept, base+len(v)
+			t.next = &responseCursor{Path: t.path, Offset: base + kept}
+		}
+		return replaceValueAt(doc, t.path, v[:kept])
+	default:
+		return doc
+	}
+}
+
+// truncationCandidate is a list or string of a result that can be cut
+type truncationCandidate struct {
+	path  []string
+	value any
+	size  int
+}
+
+// truncationCandidates returns the lists and strings of a result, largest first.
+func truncationCandidates(doc any) []truncationCandidate {
+	var candidates []truncationCandidate
+	var walk func(value any, path []string)
+	walk = func(value any, path []string) {
+		switch v := value.(type) {
+		case map[string]any:
+			for name, field := range v {
+				walk(field, append(path[:len(path):len(path)], name))
+			}
+		case []any:
+			candidates = append(candidates, truncationCandidate{path: path, value: v, size: jsonSize(v)})
+			for i, item := range v {
+				walk(item, append(path[:len(path):len(path)], strconv.Itoa(i)))
+			}
+		case string:
+			candidates = append(candidates, truncationCandidate{path: path, value: v, size: jsonSize(v)})
+		}
+	}
+	walk(doc, []string{})
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].before(candidates[j])
+	})
+	return candidates
+}
+
+// before reports whether a candidate is truncated before another one: larger values first, then shallower
+// values, then by path for stable results.
+func (c truncationCandidate) before(other truncationCandidate) bool {
+	if c.size != other.size {
+		return c.size > other.size
+	}
+	if len(c.path) != len(other.path) {
+		return len(c.path) < len(other.path)
+	}
+	return strings.Join(c.path, ".") < strings.Join(other.path, ".")
+}
+
+// candidateQueue is a heap of truncation candidates, the one truncated first on top
+type candidateQueue []truncationCandidate
+
+func (q candidateQueue) Len() int           { return len(q) }
+func (q candidateQueue) Less(i, j int) bool { return q[i].before(q[j]) }
+func (q candidateQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *candidateQueue) Push(x any)        { *q = append(*q, x.(truncationCandidate)) }
+func (q *candidateQueue) Pop() any {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// pathKey returns a path of a result as a map key.
+func pathKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+// shortenable reports whether a value can be shortened further.
+func shortenable(value any) bool {
+	switch v := value.(type) {
+	case []any:
+		return len(v) > 1
+	case string:
+		return true
+	default:
+		return false
+	}
+}
+
+// shorten halves a list or a string.
+func shorten(value any) any {
+	switch v := value.(type) {
+	case []any:
+		return v[:len(v)/2]
+	case string:
+		v = strings.TrimSuffix(v, shortenedMarker)
+		n := len(v) / 2
+		for n > 0 && !utf8.RuneStart(v[n]) {
+			n--
+		}
+		return v[:n] + shortenedMarker
+	default:
+		return value
+	}
+}
+
+// valueAt returns the value at a path of a result, where the items of lists are numbered.
+func valueAt(doc any, path []string) (any, bool) {
+	value := doc
+	for _, name := range path {
+		switch v := value.(type) {
+		case map[string]any:
+			field, ok := v[name]
+			if !ok {
+				return nil, false
+			}
+			value = field
+		case []any:
+			i, err := strconv.Atoi(name)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// replaceValueAt replaces the value at a path of a result, which must exist, and returns the result.
+// Objects and lists are not modified, the ones on the path are copied.
+func replaceValueAt(doc any, path []string, value any) any {
+	if len(path) == 0 {
+		return value
+	}
+
+	switch v := doc.(type) {
+	case map[string]any:
+		replaced := make(map[string]any, len(v))
+		for name, field := range v {
+			replaced[name] = field
+		}
+		replaced[path[0]] = replaceValueAt(v[path[0]], path[1:], value)
+		return replaced
+	case []any:
+		i, _ := strconv.Atoi(path[0])
+		replaced := append([]any(nil), v...)
+		replaced[i] = replaceValueAt(v[i], path[1:], value)
+		return replaced
+	default:
+		return doc
+	}
+}
+
+// isPathPrefix reports whether prefix is a prefix of path, or path itself.
+func isPathPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// displayPath formats a path of a result like the paths of fields, $ being the whole result.
+func displayPath(path []string) string {
+	if len(path) == 0 {
+		return "$"
+	}
+	return "$." + strings.Join(path, ".")
+}
+
+// jsonSize returns the size of the JSON encoding of a value.
+func jsonSize(value any) int {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
@@ -0,0 +1,311 @@
+package harness
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// callShaped calls a tool returning result through ShapingMiddleware with the given arguments.
+func callShaped(t *testing.T, result func() (*mcp.CallToolResult, error), args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	handler := ShapingMiddleware()(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return result()
+	})
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = args
+	shaped, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return shaped
+}
+
+// nextCursor returns the cursor of a truncated result, or "" if it is complete.
+func nextCursor(result *mcp.CallToolResult) string {
+	if result.Meta == nil {
+		return ""
+	}
+	cursor, _ := result.Meta.AdditionalFields[nextCursorMetaKey].(string)
+	return cursor
+}
+
+func TestSelectFields(t *testing.T) {
+	doc := map[string]any{
+		"name": "build",
+		"data": map[string]any{
+			"content": []any{
+				map[string]any{"name": "a", "status": "Success", "tags": map[string]any{"env": "prod"}},
+				map[string]any{"name": "b", "status": "Failed"},
+			},
+			"totalItems": json.Number("2"),
+		},
+	}
+
+	tests := []struct {
+		name   string
+		fields string
+		want   any
+	}{
+		{
+			name:   "top level field",
+			fields: "name",
+			want:   map[string]any{"name": "build"},
+		},
+		{
+			name:   "through lists",
+			fields: "data.content.name",
+			want: map[string]any{"data": map[string]any{"content": []any{
+				map[string]any{"name": "a"},
+				map[string]any{"name": "b"},
+			}}},
+		},
+		{
+			name:   "JSONPath syntax",
+			fields: "$.data.content[*].status",
+			want: map[string]any{"data": map[string]any{"content": []any{
+				map[string]any{"status": "Success"},
+				map[string]any{"status": "Failed"},
+			}}},
+		},
+		{
+			name:   "wildcard",
+			fields: "data.content.tags.*",
+			want: map[string]any{"data": map[string]any{"content": []any{
+				map[string]any{"tags": map[string]any{"env": "prod"}},
+				map[string]any{},
+			}}},
+		},
+		{
+			name:   "shorter path wins",
+			fields: "data.content.name,data",
+			want:   map[string]any{"data": doc["data"]},
+		},
+		{
+			name:   "path past a scalar",
+			fields: "name.first,data.totalItems",
+			want:   map[string]any{"data": map[string]any{"totalItems": json.Number("2")}},
+		},
+		{
+			name:   "whole result",
+			fields: "$",
+			want:   doc,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths [][]string
+			for _, field := range strings.Split(tt.fields, ",") {
+				path, err := parseFieldPath(field)
+				if err != nil {
+					t.Fatalf("parseFieldPath(%q): %v", field, err)
+				}
+				paths = append(paths, path)
+			}
+
+			got := selectFields(doc, newFieldTree(paths))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectFields(%s) = %v, want %v", tt.fields, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFieldPathInvalid(t *testing.T) {
+	for _, field := range []string{"data..name", "data.[]", "data."} {
+		if _, err := parseFieldPath(field); err == nil {
+			t.Errorf("parseFieldPath(%q) succeeded, want an error", field)
+		}
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []responseCursor{
+		{Path: []string{"data", "content"}, Offset: 20},
+		{Path: []string{"steps", "0", "log"}, Offset: 4096},
+		{Offset: 0},
+	}
+	for _, cursor := range tests {
+		got, err := decodeCursor(encodeCursor(cursor))
+		if err != nil {
+			t.Fatalf("decodeCursor: %v", err)
+		}
+		if len(got.Path) != len(cursor.Path) || !isPathPrefix(got.Path, cursor.Path) || got.Offset != cursor.Offset {
+			t.Errorf("decodeCursor(encodeCursor(%v)) = %v", cursor, *got)
+		}
+	}
+
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24", encodeCursor(responseCursor{Offset: -1})} {
+		if _, err := decodeCursor(cursor); err == nil {
+			t.Errorf("decodeCursor(%q) succeeded, want an error", cursor)
+		}
+	}
+}
+
+func TestTruncatePages(t *testing.T) {
+	type item struct {
+		Name  string `json:"name"`
+		Notes string `json:"notes"`
+	}
+	tests := []struct {
+		name     string
+		items    []item
+		maxChars int
+	}{
+		{
+			name:     "fits",
+			items:    []item{{Name: "a", Notes: "short"}},
+			maxChars: 500,
+		},
+		{
+			name: "many small items",
+			items: func() []item {
+				items := make([]item, 200)
+				for i := range items {
+					items[i] = item{Name: strings.Repeat("x", i%7+1), Notes: "note"}
+				}
+				return items
+			}(),
+			maxChars: 500,
+		},
+		{
+			name:     "items larger than the budget",
+			items:    []item{{Name: "a", Notes: strings.Repeat("é", 400)}, {Name: "b", Notes: strings.Repeat("ü", 400)}},
+			maxChars: 500,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			args := map[string]any{"max_chars": float64(tt.maxChars)}
+			for page := 0; ; page++ {
+				if page > len(tt.items) {
+					t.Fatalf("cursor does not move forward after %d pages", page)
+				}
+				result := callShaped(t, func() (*mcp.CallToolResult, error) {
+					return structuredListResult(tt.items, "items")
+				}, args)
+				if result.IsError {
+					t.Fatalf("page %d failed: %v", page, result.Content)
+				}
+
+				text := result.Content[0].(mcp.TextContent).Text
+				var items []item
+				if err := json.Unmarshal([]byte(text), &items); err != nil {
+					t.Fatalf("page %d is not JSON: %v", page, err)
+				}
+				for _, item := range items {
+					if !utf8.ValidString(item.Notes) {
+						t.Errorf("page %d has invalid UTF-8: %q", page, item.Notes)
+					}
+					names = append(names, item.Name)
+				}
+
+				cursor := nextCursor(result)
+				if cursor == "" {
+					break
+				}
+				args["cursor"] = cursor
+			}
+
+			var want []string
+			for _, item := range tt.items {
+				want = append(want, item.Name)
+			}
+			if !reflect.DeepEqual(names, want) {
+				t.Errorf("pages have items %v, want %v", names, want)
+			}
+		})
+	}
+}
+
+func TestTruncateShortensOthers(t *testing.T) {
+	doc := map[string]any{
+		"id":      "exec-1",
+		"log":     strings.Repeat("line\n", 400),
+		"yaml":    strings.Repeat("step: run\n", 200),
+		"summary": strings.Repeat("a", 100),
+		"stages":  []any{"build", "test", "deploy"},
+	}
+
+	for _, maxChars := range []int{500, 1000, 2000} {
+		shaped, cut := truncate(doc, maxChars, nil)
+		if cut == nil {
+			t.Fatalf("truncate(%d) did not truncate", maxChars)
+		}
+		if size := jsonSize(shaped); size > maxChars {
+			t.Errorf("truncate(%d) returned %d characters", maxChars, size)
+		}
+		if got := shaped.(map[string]any)["id"]; got != "exec-1" {
+			t.Errorf("truncate(%d) changed a short value: %v", maxChars, got)
+		}
+		if cut.next == nil || displayPath(cut.next.Path) != "$.log" {
+			t.Errorf("truncate(%d) cut %v, want the largest value $.log", maxChars, cut.next)
+		}
+	}
+}
+
+func TestShapeTextResult(t *testing.T) {
+	text := strings.Repeat("ünïcode log line ✓\n", 100)
+
+	tests := []struct {
+		name     string
+		maxChars int
+	}{
+		{name: "whole text"},
+		{name: "pages", maxChars: 500},
+		{name: "small pages", maxChars: minMaxChars + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]any{}
+			if tt.maxChars > 0 {
+				args["max_chars"] = float64(tt.maxChars)
+			}
+			var got strings.Builder
+			for page := 0; ; page++ {
+				if page > len(text) {
+					t.Fatalf("cursor does not move forward after %d pages", page)
+				}
+				result := callShaped(t, func() (*mcp.CallToolResult, error) {
+					return mcp.NewToolResultText(text), nil
+				}, args)
+				if result.IsError {
+					t.Fatalf("page %d failed: %v", page, result.Content)
+				}
+
+				chunk := result.Content[0].(mcp.TextContent).Text
+				if !utf8.ValidString(chunk) {
+					t.Errorf("page %d is cut inside a character", page)
+				}
+				if tt.maxChars > 0 && len(chunk) > tt.maxChars {
+					t.Errorf("page %d has %d bytes", page, len(chunk))
+				}
+				got.WriteString(chunk)
+
+				cursor := nextCursor(result)
+				if cursor == "" {
+					break
+				}
+				args["cursor"] = cursor
+			}
+			if got.String() != text {
+				t.Errorf("pages do not add up to the text")
+			}
+		})
+	}
+}
+
+func TestShapeTextResultForeignCursor(t *testing.T) {
+	result := callShaped(t, func() (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("log"), nil
+	}, map[string]any{"cursor": encodeCursor(responseCursor{Path: []string{"items"}, Offset: 1})})
+	if !result.IsError {
+		t.Errorf("a cursor of a structured result was accepted for a text result")
+	}
+}
@@ -0,0 +1,75 @@
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// schemaReflector derives the output schemas of tools from the types they return, mostly dto types.
+// Schemas are inlined since clients may not resolve references, and no property is required so that
+// omitted fields and trimmed results still match.
+var schemaReflector = jsonschema.Reflector{
+	DoNotReference:             true,
+	Anonymous:                  true,
+	AllowAdditionalProperties:  true,
+	RequiredFromJSONSchemaTags: true,
+}
+
+// listResult is the structured content of tools returning a list, since structured content must be an object
+type listResult[T any] struct {
+	Items []T `json:"items"`
+}
+
+// WithOutputSchema sets the output schema of a tool to the JSON schema of T. The tool must return
+// results made by structuredResult with a value of type T.
+func WithOutputSchema[T any]() mcp.ToolOption {
+	return mcp.WithRawOutputSchema(outputSchema[T]())
+}
+
+// WithListOutputSchema sets the output schema of a tool returning a list of T. The tool must return
+// results made by structuredListResult.
+func WithListOutputSchema[T any]() mcp.ToolOption {
+	return WithOutputSchema[listResult[T]]()
+}
+
+// outputSchema reflects the JSON schema of T.
+func outputSchema[T any]() json.RawMessage {
+	var zero T
+	schema := schemaReflector.Reflect(zero)
+	schema.Version = ""
+	// the MCP spec requires output schemas to describe objects
+	schema.Type = "object"
+
+	r, err := json.Marshal(schema)
+	if err != nil {
+		// schemas of Go types always marshal, so this is a programming error
+		panic(fmt.Sprintf("failed to marshal output schema of %T: %v", zero, err))
+	}
+	return r
+}
+
+// structuredResult returns v both as the JSON text and as the structured content of a tool result.
+// what names v in errors.
+func structuredResult(v any, what string) (*mcp.CallToolResult, error) {
+	r, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", what, err)
+	}
+	return mcp.NewToolResultStructured(v, string(r)), nil
+}
+
+// structuredListResult returns items as the JSON text of a tool result, and as the items of its structured content.
+// what names the items in errors.
+func structuredListResult[T any](items []T, what string) (*mcp.CallToolResult, error) {
+	if items == nil {
+		items = []T{}
+	}
+	r, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", what, err)
+	}
+	return mcp.NewToolResultStructured(listResult[T]{Items: items}, string(r)), nil
+}
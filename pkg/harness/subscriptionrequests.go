@@ -0,0 +1,134 @@
+package harness
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// mcp-go has no handler for resources/subscribe and resources/unsubscribe and answers them with
+// "method not found". The transports therefore rewrite these requests to pings carrying the subscription
+// in their _meta. The Subscriptions hook handles the subscription before the server answers the ping
+// with the empty result that both methods expect, or with an error if the subscription is invalid.
+
+const (
+	methodResourcesSubscribe   = "resources/subscribe"
+	methodResourcesUnsubscribe = "resources/unsubscribe"
+
+	// subscriptionMetaKey is the _meta key of the subscription carried by a rewritten request
+	subscriptionMetaKey = "harness.io/subscription"
+
+	// maxPostedMessageSize is the maximum size of a message posted to the HTTP transports, which are read
+	// in full before the server authenticates them
+	maxPostedMessageSize = 4 << 20
+)
+
+// subscriptionRequest is a subscribe or unsubscribe request carried by a ping
+type subscriptionRequest struct {
+	Method string `json:"method"`
+	URI    string `json:"uri"`
+}
+
+// rewriteSubscriptionRequest rewrites a resources/subscribe or resources/unsubscribe request to a ping
+// carrying the subscription, and reports whether the message was one.
+func rewriteSubscriptionRequest(message []byte) ([]byte, bool) {
+	// peek at the method first, so that other messages are not decoded
+	var envelope struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return message, false
+	}
+	if envelope.Method != methodResourcesSubscribe && envelope.Method != methodResourcesUnsubscribe {
+		return message, false
+	}
+
+	var request struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Method  string          `json:"method"`
+		Params  struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil || len(request.ID) == 0 {
+		return message, false
+	}
+
+	rewritten, err := json.Marshal(map[string]any{
+		"jsonrpc": request.JSONRPC,
+		"id":      request.ID,
+		"method":  mcp.MethodPing,
+		"params": map[string]any{
+			"_meta": map[string]any{
+				subscriptionMetaKey: subscriptionRequest{Method: request.Method, URI: request.Params.URI},
+			},
+		},
+	})
+	if err != nil {
+		return message, false
+	}
+	return rewritten, true
+}
+
+// SubscriptionRequestReader wraps the input of the stdio transport, which has one message per line,
+// and rewrites the subscription requests read from it.
+func SubscriptionRequestReader(r io.Reader) io.Reader {
+	return &subscriptionRequestReader{r: bufio.NewReader(r)}
+}
+
+type subscriptionRequestReader struct {
+	r   *bufio.Reader
+	buf []byte
+	err error
+}
+
+func (s *subscriptionRequestReader) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+
+		var line []byte
+		line, s.err = s.r.ReadBytes('\n')
+		if rewritten, ok := rewriteSubscriptionRequest(bytes.TrimSpace(line)); ok {
+			line = append(rewritten, '\n')
+		}
+		s.buf = line
+	}
+
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// SubscriptionRequestMiddleware rewrites the subscription requests posted to the SSE and streamable-HTTP transports.
+// Messages larger than maxPostedMessageSize are rejected.
+func SubscriptionRequestMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.Body != nil {
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPostedMessageSize))
+			_ = r.Body.Close()
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			if err != nil {
+				http.Error(w, "Failed to read request body", http.StatusBadRequest)
+				return
+			}
+
+			body, _ = rewriteSubscriptionRequest(body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,56 @@
+package harness
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// postThroughMiddleware posts body through SubscriptionRequestMiddleware and returns the response
+// and the body the wrapped handler received.
+func postThroughMiddleware(t *testing.T, body string) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+	var received string
+	handler := SubscriptionRequestMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received = string(data)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body)))
+	return w, received
+}
+
+func TestSubscriptionRequestMiddlewareRewritesSubscriptions(t *testing.T) {
+	w, received := postThroughMiddleware(t, `{"jsonrpc":"2.0","id":7,"method":"resources/subscribe","params":{"uri":"harness://o/p/executions/e"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if !strings.Contains(received, `"method":"ping"`) || !strings.Contains(received, `"uri":"harness://o/p/executions/e"`) {
+		t.Errorf("subscribe request was not rewritten to a ping: %s", received)
+	}
+}
+
+func TestSubscriptionRequestMiddlewareLeavesOtherMessages(t *testing.T) {
+	// the arguments mention subscribe, the method does not
+	body := `{"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"search_execution_logs","arguments":{"query":"resources/subscribe"}}}`
+	_, received := postThroughMiddleware(t, body)
+	if received != body {
+		t.Errorf("message was changed to %s", received)
+	}
+}
+
+func TestSubscriptionRequestMiddlewareRejectsLargeBodies(t *testing.T) {
+	w, received := postThroughMiddleware(t, `{"method":"tools/call","params":"`+strings.Repeat("x", maxPostedMessageSize)+`"}`)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+	if received != "" {
+		t.Error("the large body was passed on")
+	}
+}
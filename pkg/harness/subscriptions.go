@@ -0,0 +1,426 @@
+package harness
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/harness/harness-mcp/client"
+	"github.com/harness/harness-mcp/cmd/harness-mcp-server/config"
+	"github.com/harness/harness-mcp/pkg/toolsets"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Polling intervals of subscribed resources. Polling starts at the minimum interval and backs off
+// up to the maximum while the resource does not change, so idle subscriptions cost few API calls.
+const (
+	executionPollMinInterval   = 5 * time.Second
+	executionPollMaxInterval   = time.Minute
+	pullRequestPollMinInterval = 15 * time.Second
+	pullRequestPollMaxInterval = 2 * time.Minute
+
+	// a resource is no longer polled after this many consecutive failures
+	maxPollFailures = 10
+)
+
+// resourcePollFunc fetches the state of a subscribed resource and reports whether it is final
+type resourcePollFunc func(ctx context.Context, request mcp.ReadResourceRequest) (state string, terminal bool, err error)
+
+// subscribableResource is a resource template whose resources can be subscribed to
+type subscribableResource struct {
+	template    *mcp.URITemplate
+	minInterval time.Duration
+	maxInterval time.Duration
+	poll        resourcePollFunc
+}
+
+// watchKey identifies a watch. Sessions share a watch only if they subscribed to the same resource
+// with the same credentials, so that updates are never polled with the credentials of another caller.
+type watchKey struct {
+	credentials string
+	uri         string
+}
+
+// resourceWatch polls a subscribed resource on behalf of all the sessions subscribed to it
+type resourceWatch struct {
+	key      watchKey
+	resource subscribableResource
+	request  mcp.ReadResourceRequest
+	cancel   context.CancelFunc
+	// sessions maps the IDs of the subscribed sessions to the contexts they subscribed with,
+	// which carry the credentials used to poll
+	sessions map[string]context.Context
+	// generation counts the sessions that joined the watch after it started, see stopWatch
+	generation int
+}
+
+// Subscriptions tracks the subscriptions to execution and pull request resources. Each subscribed
+// resource is polled by a single watcher shared by all sessions subscribed with the same credentials,
+// which are sent a notifications/resources/updated notification when its status changes.
+type Subscriptions struct {
+	server *server.MCPServer
+	client *client.Client
+	config *config.Config
+
+	mu        sync.Mutex
+	resources []subscribableResource
+	watches   map[watchKey]*resourceWatch
+}
+
+// NewSubscriptions creates the subscriptions of the server for the resources of the enabled toolsets.
+func NewSubscriptions(s *server.MCPServer, tsg *toolsets.ToolsetGroup, client *client.Client, config *config.Config) *Subscriptions {
+	subscriptions := &Subscriptions{
+		server:  s,
+		client:  client,
+		config:  config,
+		watches: make(map[watchKey]*resourceWatch),
+	}
+
+	for _, name := range toolsetNames(tsg) {
+		if tsg.IsEnabled(name) {
+			subscriptions.AddToolset(name)
+		}
+	}
+
+	return subscriptions
+}
+
+// AddToolset makes the resources of a toolset subscribable, e.g. when the toolset is enabled at runtime.
+func (s *Subscriptions) AddToolset(name string) {
+	var resource subscribableResource
+	switch name {
+	case "pipelines":
+		resource = subscribableResource{
+			template:    mcp.NewResourceTemplate(executionResourceTemplate, "").URITemplate,
+			minInterval: executionPollMinInterval,
+			maxInterval: executionPollMaxInterval,
+			poll:        pollExecution(s.config, s.client),
+		}
+	case "pullrequests":
+		resource = subscribableResource{
+			template:    mcp.NewResourceTemplate(pullRequestResourceTemplate, "").URITemplate,
+			minInterval: pullRequestPollMinInterval,
+			maxInterval: pullRequestPollMaxInterval,
+			poll:        pollPullRequest(s.config, s.client),
+		}
+	default:
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resources = append(s.resources, resource)
+}
+
+// AddHooks adds the hooks handling the subscription requests and the end of sessions to the server hooks.
+func (s *Subscriptions) AddHooks(hooks *server.Hooks) {
+	hooks.AddOnRequestInitialization(s.handleRequest)
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		s.removeSession(session.SessionID())
+	})
+}
+
+// handleRequest handles the subscription carried by a rewritten subscription request, see subscriptionrequests.go.
+// Returning an error makes the server answer the request with it.
+func (s *Subscriptions) handleRequest(ctx context.Context, _ any, message any) error {
+	raw, ok := message.(json.RawMessage)
+	if !ok || !bytes.Contains(raw, []byte(subscriptionMetaKey)) {
+		return nil
+	}
+
+	var request struct {
+		Method string `json:"method"`
+		Params struct {
+			Meta map[string]subscriptionRequest `json:"_meta"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(raw, &request); err != nil || request.Method != string(mcp.MethodPing) {
+		return nil
+	}
+	subscription, ok := request.Params.Meta[subscriptionMetaKey]
+	if !ok {
+		return nil
+	}
+
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return fmt.Errorf("subscriptions require a session")
+	}
+
+	switch subscription.Method {
+	case methodResourcesSubscribe:
+		return s.Subscribe(ctx, session.SessionID(), subscription.URI)
+	case methodResourcesUnsubscribe:
+		s.Unsubscribe(session.SessionID(), subscription.URI)
+	}
+	return nil
+}
+
+// Subscribe subscribes a session to a resource. The resource is read once with the credentials of ctx,
+// so that only callers allowed to read it can subscribe, and then polled with the same credentials.
+func (s *Subscriptions) Subscribe(ctx context.Context, sessionID, uri string) error {
+	resource, request, err := s.match(uri)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := resource.poll(ctx, request); err != nil {
+		return fmt.Errorf("failed to read subscribed resource: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// polls outlive the subscribe request, but need the values of its context
+	sessionCtx := context.WithoutCancel(ctx)
+	key := watchKey{credentials: client.CredentialsID(ctx), uri: uri}
+	if watch, ok := s.watches[key]; ok {
+		watch.sessions[sessionID] = sessionCtx
+		watch.generation++
+		return nil
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	watch := &resourceWatch{
+		key:      key,
+		resource: resource,
+		request:  request,
+		cancel:   cancel,
+		sessions: map[string]context.Context{sessionID: sessionCtx},
+	}
+	s.watches[key] = watch
+	go s.watch(watchCtx, watch)
+
+	slog.Debug("Subscribed to resource", "uri", uri, "session", sessionID)
+	return nil
+}
+
+// Unsubscribe unsubscribes a session from a resource, the resource is no longer polled once it has no subscribers.
+func (s *Subscriptions) Unsubscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, watch := range s.watches {
+		if key.uri == uri {
+			s.unsubscribe(sessionID, watch)
+		}
+	}
+}
+
+// removeSession unsubscribes a session from all resources.
+func (s *Subscriptions) removeSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, watch := range s.watches {
+		s.unsubscribe(sessionID, watch)
+	}
+}
+
+// unsubscribe removes a session from a watch, and the watch once it has no subscribers. s.mu must be held.
+func (s *Subscriptions) unsubscribe(sessionID string, watch *resourceWatch) {
+	delete(watch.sessions, sessionID)
+	if len(watch.sessions) == 0 {
+		watch.cancel()
+		delete(s.watches, watch.key)
+	}
+}
+
+// match finds the subscribable resource of a URI.
+func (s *Subscriptions) match(uri string) (subscribableResource, mcp.ReadResourceRequest, error) {
+	s.mu.Lock()
+	resources := s.resources
+	s.mu.Unlock()
+
+	for _, resource := range resources {
+		values := resource.template.Match(uri)
+		if values == nil {
+			continue
+		}
+
+		request := mcp.ReadResourceRequest{}
+		request.Params.URI = uri
+		request.Params.Arguments = make(map[string]any, len(values))
+		for name, value := range values {
+			request.Params.Arguments[name] = value.V
+		}
+		return resource, request, nil
+	}
+
+	return subscribableResource{}, mcp.ReadResourceRequest{}, fmt.Errorf("subscriptions are only supported for execution and pull request resources of enabled toolsets: %s", uri)
+}
+
+// watch polls a resource until it has no subscribers, reaches a final state or keeps failing to be polled.
+func (s *Subscriptions) watch(ctx context.Context, watch *resourceWatch) {
+	interval := watch.resource.minInterval
+	var lastState string
+	seen := false
+	failures := 0
+	for {
+		state, terminal, generation, err := s.poll(ctx, watch)
+		done := false
+		switch {
+		case ctx.Err() != nil:
+			s.stopWatch(watch, generation)
+			return
+		case err != nil:
+			failures++
+			slog.Warn("Failed to poll subscribed resource", "uri", watch.key.uri, "failures", failures, "error", err)
+			done = failures >= maxPollFailures
+			interval = min(interval*2, watch.resource.maxInterval)
+		case !seen:
+			// the first state is the one the subscriber has already read
+		case state != lastState:
+			slog.Debug("Subscribed resource changed", "uri", watch.key.uri, "state", state)
+			s.notify(watch)
+			interval = watch.resource.minInterval
+		default:
+			interval = min(interval*2, watch.resource.maxInterval)
+		}
+		if err == nil {
+			lastState, seen, failures = state, true, 0
+			done = terminal
+		}
+		if done {
+			if s.stopWatch(watch, generation) {
+				return
+			}
+			// sessions subscribed since the poll, poll again for them
+			failures, interval = 0, watch.resource.minInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// poll polls a resource with the context of one of its subscribers, and returns the generation of the
+// watch at the time of the poll.
+func (s *Subscriptions) poll(ctx context.Context, watch *resourceWatch) (string, bool, int, error) {
+	s.mu.Lock()
+	var sessionCtx context.Context
+	for _, c := range watch.sessions {
+		sessionCtx = c
+		break
+	}
+	generation := watch.generation
+	s.mu.Unlock()
+	if sessionCtx == nil {
+		return "", false, generation, context.Canceled
+	}
+
+	// stop the poll when the watch is stopped
+	pollCtx, cancel := context.WithCancel(sessionCtx)
+	defer cancel()
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	state, terminal, err := watch.resource.poll(pollCtx, watch.request)
+	return state, terminal, generation, err
+}
+
+// notify sends a notifications/resources/updated notification to the subscribers of a resource,
+// sessions that have ended are unsubscribed.
+func (s *Subscriptions) notify(watch *resourceWatch) {
+	s.mu.Lock()
+	sessionIDs := make([]string, 0, len(watch.sessions))
+	for sessionID := range watch.sessions {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	s.mu.Unlock()
+
+	for _, sessionID := range sessionIDs {
+		err := s.server.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": watch.key.uri})
+		switch {
+		case errors.Is(err, server.ErrSessionNotFound):
+			s.mu.Lock()
+			s.unsubscribe(sessionID, watch)
+			s.mu.Unlock()
+		case err != nil:
+			slog.Warn("Failed to notify subscriber of resource update", "uri", watch.key.uri, "session", sessionID, "error", err)
+		}
+	}
+}
+
+// stopWatch removes a watch that stopped polling along with its subscriptions. If sessions subscribed
+// since the poll of the given generation, the watch is kept for them and stopWatch reports false.
+// The check and the removal are done under the same lock, so that no subscription is lost.
+func (s *Subscriptions) stopWatch(watch *resourceWatch, generation int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if watch.generation != generation && len(watch.sessions) > 0 {
+		return false
+	}
+
+	watch.cancel()
+	if s.watches[watch.key] == watch {
+		delete(s.watches, watch.key)
+	}
+	slog.Debug("Stopped polling subscribed resource", "uri", watch.key.uri)
+	return true
+}
+
+// pollExecution polls the status of an execution, which is final once the execution has finished
+func pollExecution(config *config.Config, client *client.Client) resourcePollFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) (string, bool, error) {
+		scope, err := resourceScope(config, request)
+		if err != nil {
+			return "", false, err
+		}
+		planExecutionID, err := resourceArgument(request, "plan_execution_id")
+		if err != nil {
+			return "", false, err
+		}
+
+		data, err := client.Pipelines.GetExecution(ctx, scope, planExecutionID)
+		if err != nil {
+			return "", false, errors.New(describeAPIError(err, "get execution", scope))
+		}
+
+		status := data.Data.Status
+		return status, isTerminalStatus(status), nil
+	}
+}
+
+// pollPullRequest polls the state of a pull request, including its draft state, source commit and mergeability.
+// Only merged pull requests are final, since closed ones can be reopened.
+func pollPullRequest(config *config.Config, client *client.Client) resourcePollFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) (string, bool, error) {
+		scope, err := resourceScope(config, request)
+		if err != nil {
+			return "", false, err
+		}
+		repoID, err := resourceArgument(request, "repo")
+		if err != nil {
+			return "", false, err
+		}
+		number, err := resourceArgument(request, "number")
+		if err != nil {
+			return "", false, err
+		}
+		prNumber, err := strconv.Atoi(number)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid pull request number: %s", number)
+		}
+
+		pr, err := client.PullRequests.Get(ctx, scope, repoID, prNumber)
+		if err != nil {
+			return "", false, errors.New(describeAPIError(err, "get pull request", scope))
+		}
+
+		state := strings.Join([]string{pr.State, strconv.FormatBool(pr.IsDraft), pr.SourceSha, pr.MergeCheckStatus}, "/")
+		return state, strings.EqualFold(pr.State, "merged"), nil
+	}
+}
@@ -2,6 +2,8 @@ package harness
 
 import (
 	"context"
+	"net/http"
+	"strings"
 
 	"github.com/harness/harness-mcp/client"
 	"github.com/harness/harness-mcp/cmd/harness-mcp-server/config"
@@ -25,6 +27,15 @@ func InitToolsets(client *client.Client, config *config.Config) (*toolsets.Tools
 			toolsets.NewServerTool(FetchExecutionURLTool(config, client)),
 			toolsets.NewServerTool(GetExecutionTool(config, client)),
 			toolsets.NewServerTool(ListExecutionsTool(config, client)),
+			toolsets.NewServerTool(GetExecutionRetryInfoTool(config, client)),
+			toolsets.NewServerTool(GetExecutionGraphTool(config, client)),
+		).
+		AddWriteTools(
+			toolsets.NewServerTool(RunPipelineTool(config, client)),
+			toolsets.NewServerTool(AbortExecutionTool(config, client)),
+			toolsets.NewServerTool(PauseExecutionTool(config, client)),
+			toolsets.NewServerTool(ResumeExecutionTool(config, client)),
+			toolsets.NewServerTool(RetryExecutionTool(config, client)),
 		)
 
 	// Create the pull requests toolset
@@ -33,9 +44,23 @@ func InitToolsets(client *client.Client, config *config.Config) (*toolsets.Tools
 			toolsets.NewServerTool(GetPullRequestTool(config, client)),
 			toolsets.NewServerTool(ListPullRequestsTool(config, client)),
 			toolsets.NewServerTool(GetPullRequestChecksTool(config, client)),
+			toolsets.NewServerTool(ListPullRequestActivitiesTool(config, client)),
+			toolsets.NewServerTool(ListPullRequestReviewersTool(config, client)),
+			toolsets.NewServerTool(GetPullRequestDiffTool(config, client)),
 		).
 		AddWriteTools(
 			toolsets.NewServerTool(CreatePullRequestTool(config, client)),
+			toolsets.NewServerTool(UpdatePullRequestTool(config, client)),
+			toolsets.NewServerTool(ClosePullRequestTool(config, client)),
+			toolsets.NewServerTool(ReopenPullRequestTool(config, client)),
+			toolsets.NewServerTool(SetPullRequestDraftTool(config, client)),
+			toolsets.NewServerTool(MergePullRequestTool(config, client)),
+			toolsets.NewServerTool(CreatePullRequestCommentTool(config, client)),
+			toolsets.NewServerTool(ReplyToPullRequestCommentTool(config, client)),
+			toolsets.NewServerTool(ResolvePullRequestCommentTool(config, client)),
+			toolsets.NewServerTool(AddPullRequestReviewerTool(config, client)),
+			toolsets.NewServerTool(RemovePullRequestReviewerTool(config, client)),
+			toolsets.NewServerTool(SubmitPullRequestReviewTool(config, client)),
 		)
 
 	// Create the repositories toolset
@@ -49,6 +74,10 @@ func InitToolsets(client *client.Client, config *config.Config) (*toolsets.Tools
 	logs := toolsets.NewToolset("logs", "Harness Logs related tools").
 		AddReadTools(
 			toolsets.NewServerTool(DownloadExecutionLogsTool(config, client)),
+			toolsets.NewServerTool(GetStepLogsTool(config, client)),
+			toolsets.NewServerTool(TailExecutionLogsTool(config, client)),
+			toolsets.NewServerTool(SearchExecutionLogsTool(config, client)),
+			toolsets.NewServerTool(SummarizeExecutionFailureTool(config, client)),
 		)
 
 	// Add toolsets to the group
@@ -58,7 +87,17 @@ func InitToolsets(client *client.Client, config *config.Config) (*toolsets.Tools
 	tsg.AddToolset(logs)
 
 	// Enable requested toolsets
-	if err := tsg.EnableToolsets(config.Toolsets); err != nil {
+	enabled := config.Toolsets
+	if config.DynamicToolsets {
+		// in dynamic mode toolsets are enabled as needed, so "all" is ignored
+		enabled = make([]string, 0, len(config.Toolsets))
+		for _, name := range config.Toolsets {
+			if name != "all" {
+				enabled = append(enabled, name)
+			}
+		}
+	}
+	if err := tsg.EnableToolsets(enabled); err != nil {
 		return nil, err
 	}
 
@@ -67,10 +106,27 @@ func InitToolsets(client *client.Client, config *config.Config) (*toolsets.Tools
 
 // SetupContextWithApiKey sets up the context with the API key
 func SetupContextWithApiKey(ctx context.Context, apiKey string) context.Context {
-	return context.WithValue(ctx, "apiKey", apiKey)
+	return client.WithAPIKey(ctx, apiKey)
 }
 
 // SetupContextWithBearerToken sets up the context with the bearer token
 func SetupContextWithBearerToken(ctx context.Context, bearerToken string) context.Context {
-	return context.WithValue(ctx, "bearerToken", bearerToken)
+	return client.WithBearerToken(ctx, bearerToken)
+}
+
+// SetupContextFromHTTPRequest sets up the context with the credentials sent on an incoming
+// MCP HTTP request. The x-api-key header is preferred over an Authorization bearer token.
+// If neither is present the context is returned unchanged and the server's configured
+// API key is used instead.
+func SetupContextFromHTTPRequest(ctx context.Context, r *http.Request) context.Context {
+	if apiKey := strings.TrimSpace(r.Header.Get("x-api-key")); apiKey != "" {
+		return SetupContextWithApiKey(ctx, apiKey)
+	}
+
+	authorization := strings.TrimSpace(r.Header.Get("Authorization"))
+	if len(authorization) > len("Bearer ") && strings.EqualFold(authorization[:len("Bearer ")], "Bearer ") {
+		return SetupContextWithBearerToken(ctx, strings.TrimSpace(authorization[len("Bearer "):]))
+	}
+
+	return ctx
 }
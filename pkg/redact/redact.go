@@ -0,0 +1,183 @@
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+const (
+	// minimum length of a string checked for high entropy, shorter strings include Harness identifiers
+	minEntropyLength = 32
+	// minimum Shannon entropy in bits per character of a string considered a secret,
+	// above what hex encoded hashes and identifiers can reach
+	minEntropy = 4.5
+)
+
+// detector finds secrets of one kind.
+type detector struct {
+	name string
+	re   *regexp.Regexp
+	// group is the capture group holding the secret, 0 redacts the whole match
+	group int
+}
+
+// builtinDetectors find common token formats and secrets in connection strings and assignments.
+var builtinDetectors = []detector{
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), 0},
+	{"harness_token", regexp.MustCompile(`\b(?:pat|sat)\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`), 0},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`), 0},
+	{"gitlab_token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`), 0},
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[A-Z0-9]{16}\b`), 0},
+	{"slack_token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`), 0},
+	{"google_api_key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`), 0},
+	{"stripe_key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}`), 0},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`), 0},
+	{"bearer_token", regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/-]{16,}=*)`), 1},
+	{"connection_string", regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^:/\s@]+:([^@\s/]+)@`), 1},
+	{"secret_assignment", regexp.MustCompile(`(?i)(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|client[_-]?secret)(?:\\?["'])?\s*[:=]\s*(?:\\?["'])?([^\s"',;&\\]{4,})`), 1},
+	{"secret_expression", regexp.MustCompile(`<\+secrets\.getValue\(\s*([^)]*?)\s*\)>`), 1},
+}
+
+// values of assignments that are not secrets, e.g. in "token_type: bearer" like fields or masked values
+var ignoredValues = map[string]bool{
+	"true": true, "false": true, "null": true, "none": true, "nil": true, "bearer": true,
+	"redacted": true, "required": true, "optional": true,
+}
+
+// highEntropyCandidate matches strings that may be random tokens or keys
+var highEntropyCandidate = regexp.MustCompile(fmt.Sprintf(`[A-Za-z0-9+/_=-]{%d,}`, minEntropyLength))
+
+// Options configures a Redactor.
+type Options struct {
+	// Patterns are additional regular expressions of secrets. If a pattern has capture groups,
+	// only the first group is redacted, otherwise the whole match is.
+	Patterns []string
+	// HighEntropy enables the detection of long random strings, which are likely keys or tokens
+	HighEntropy bool
+}
+
+// Redactor replaces secrets in text with a placeholder naming the kind of secret.
+type Redactor struct {
+	detectors   []detector
+	highEntropy bool
+}
+
+// New creates a redactor with the built-in detectors and the patterns of opts.
+func New(opts Options) (*Redactor, error) {
+	detectors := append([]detector(nil), builtinDetectors...)
+	for i, pattern := range opts.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		d := detector{name: fmt.Sprintf("custom_%d", i+1), re: re}
+		if re.NumSubexp() > 0 {
+			d.group = 1
+		}
+		detectors = append(detectors, d)
+	}
+
+	return &Redactor{detectors: detectors, highEntropy: opts.HighEntropy}, nil
+}
+
+// Redact returns s with all secrets replaced, and the number of secrets that were replaced.
+func (r *Redactor) Redact(s string) (string, int) {
+	count := 0
+	for _, d := range r.detectors {
+		var n int
+		s, n = d.redact(s)
+		count += n
+	}
+
+	if r.highEntropy {
+		s = highEntropyCandidate.ReplaceAllStringFunc(s, func(candidate string) string {
+			if !isHighEntropy(candidate) {
+				return candidate
+			}
+			count++
+			return placeholder("high_entropy")
+		})
+	}
+
+	return s, count
+}
+
+// redact replaces the matches of the detector in s.
+func (d detector) redact(s string) (string, int) {
+	matches := d.re.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, 0
+	}
+
+	var b strings.Builder
+	count, last := 0, 0
+	for _, m := range matches {
+		start, end := m[2*d.group], m[2*d.group+1]
+		if start < 0 || start < last {
+			continue
+		}
+		secret := s[start:end]
+		if d.group > 0 && (ignoredValues[strings.ToLower(secret)] || isPlaceholder(secret)) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(placeholder(d.name))
+		last = end
+		count++
+	}
+	b.WriteString(s[last:])
+
+	return b.String(), count
+}
+
+func placeholder(kind string) string {
+	return "[REDACTED:" + kind + "]"
+}
+
+// isPlaceholder reports whether s was already redacted or is masked, like the values Harness masks in logs.
+func isPlaceholder(s string) bool {
+	return strings.HasPrefix(s, "[REDACTED") || strings.Trim(s, "*") == "" || strings.HasPrefix(s, "<+")
+}
+
+// isHighEntropy reports whether s looks like a random key or token rather than a word, hash or identifier.
+func isHighEntropy(s string) bool {
+	var hasLower, hasUpper, hasDigit bool
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z':
+			hasLower = true
+		case c >= 'A' && c <= 'Z':
+			hasUpper = true
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		}
+	}
+	// random tokens mix letters of both cases and digits, this rules out hex hashes and most identifiers
+	if !hasLower || !hasUpper || !hasDigit {
+		return false
+	}
+	// paths and URLs are made of several segments, a random token has few slashes if any
+	if strings.Count(s, "/") > 2 {
+		return false
+	}
+
+	return shannonEntropy(s) >= minEntropy
+}
+
+// shannonEntropy returns the entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, c := range s {
+		counts[c]++
+	}
+
+	entropy := 0.0
+	n := float64(len(s))
+	for _, count := range counts {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,19 @@
+// Package textutil has helpers for cutting text without splitting UTF-8 encoded characters.
+package textutil
+
+import "unicode/utf8"
+
+// RunePrefixLen returns the length of the longest prefix of s of at most n bytes that ends at a character
+// boundary, or of the first character if it is longer than n, so that cursors always move forward.
+func RunePrefixLen(s string, n int) int {
+	if n >= len(s) {
+		return len(s)
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	if n == 0 {
+		_, n = utf8.DecodeRuneInString(s)
+	}
+	return n
+}
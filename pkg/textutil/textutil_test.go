@@ -0,0 +1,22 @@
+package textutil
+
+import "testing"
+
+func TestRunePrefixLen(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want int
+	}{
+		{s: "abc", n: 5, want: 3},
+		{s: "abc", n: 2, want: 2},
+		{s: "aé", n: 2, want: 1},
+		{s: "éa", n: 1, want: 2},
+		{s: "✓✓", n: 4, want: 3},
+	}
+	for _, tt := range tests {
+		if got := RunePrefixLen(tt.s, tt.n); got != tt.want {
+			t.Errorf("RunePrefixLen(%q, %d) = %d, want %d", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
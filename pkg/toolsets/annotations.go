@@ -0,0 +1,49 @@
+package toolsets
+
+import (
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// words of tool names that are written in upper case in titles
+var titleAcronyms = map[string]string{
+	"id":  "ID",
+	"pr":  "PR",
+	"url": "URL",
+}
+
+// annotateReadTool annotates a tool as read-only, which makes it non-destructive and idempotent too,
+// so that clients can run it without asking for approval.
+func annotateReadTool(tool *mcp.Tool) {
+	annotateTitle(tool)
+	tool.Annotations.ReadOnlyHint = mcp.ToBoolPtr(true)
+	tool.Annotations.DestructiveHint = mcp.ToBoolPtr(false)
+	tool.Annotations.IdempotentHint = mcp.ToBoolPtr(true)
+}
+
+// annotateWriteTool annotates a tool as changing its environment. Whether it is destructive or idempotent is
+// up to the tool, which may set these hints itself with the mcp.With*HintAnnotation options. Otherwise the
+// defaults set by mcp.NewTool apply, which are those of the MCP spec: destructive, not idempotent and open world.
+func annotateWriteTool(tool *mcp.Tool) {
+	annotateTitle(tool)
+	tool.Annotations.ReadOnlyHint = mcp.ToBoolPtr(false)
+}
+
+// annotateTitle derives the title of a tool from its name, e.g. "Get Pull Request" for get_pull_request,
+// unless the tool has a title already.
+func annotateTitle(tool *mcp.Tool) {
+	if tool.Annotations.Title != "" {
+		return
+	}
+
+	words := strings.Split(tool.Name, "_")
+	for i, word := range words {
+		if acronym, ok := titleAcronyms[word]; ok {
+			words[i] = acronym
+		} else if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	tool.Annotations.Title = strings.Join(words, " ")
+}
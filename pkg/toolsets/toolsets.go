@@ -2,6 +2,8 @@ package toolsets
 
 import (
 	"fmt"
+	"slices"
+	"sync"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -28,7 +30,7 @@ func (t *Toolset) GetActiveTools() []server.ServerTool {
 		if t.readOnly {
 			return t.readTools
 		}
-		return append(t.readTools, t.writeTools...)
+		return slices.Concat(t.readTools, t.writeTools)
 	}
 	return nil
 }
@@ -38,7 +40,7 @@ func (t *Toolset) GetAvailableTools() []server.ServerTool {
 	if t.readOnly {
 		return t.readTools
 	}
-	return append(t.readTools, t.writeTools...)
+	return slices.Concat(t.readTools, t.writeTools)
 }
 
 // RegisterTools registers all enabled tools with the server
@@ -61,25 +63,33 @@ func (t *Toolset) SetReadOnly() {
 	t.readOnly = true
 }
 
-// AddWriteTools adds write tools to the toolset
+// AddWriteTools adds write tools to the toolset, annotated as changing their environment
 func (t *Toolset) AddWriteTools(tools ...server.ServerTool) *Toolset {
 	if !t.readOnly {
+		for i := range tools {
+			annotateWriteTool(&tools[i].Tool)
+		}
 		t.writeTools = append(t.writeTools, tools...)
 	}
 	return t
 }
 
-// AddReadTools adds read tools to the toolset
+// AddReadTools adds read tools to the toolset, annotated as read-only
 func (t *Toolset) AddReadTools(tools ...server.ServerTool) *Toolset {
+	for i := range tools {
+		annotateReadTool(&tools[i].Tool)
+	}
 	t.readTools = append(t.readTools, tools...)
 	return t
 }
 
-// ToolsetGroup manages multiple toolsets
+// ToolsetGroup manages multiple toolsets. Toolsets can be enabled while the server runs,
+// so whether they are enabled is read and changed under the group's lock.
 type ToolsetGroup struct {
 	Toolsets     map[string]*Toolset
 	everythingOn bool
 	readOnly     bool
+	mu           sync.RWMutex
 }
 
 // NewToolsetGroup creates a new toolset group
@@ -111,6 +121,9 @@ func NewToolset(name string, description string) *Toolset {
 
 // IsEnabled checks if a toolset is enabled
 func (tg *ToolsetGroup) IsEnabled(name string) bool {
+	tg.mu.RLock()
+	defer tg.mu.RUnlock()
+
 	if tg.everythingOn {
 		return true
 	}
@@ -124,12 +137,15 @@ func (tg *ToolsetGroup) IsEnabled(name string) bool {
 
 // EnableToolsets enables multiple toolsets by name
 func (tg *ToolsetGroup) EnableToolsets(names []string) error {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+
 	for _, name := range names {
 		if name == "all" {
 			tg.everythingOn = true
 			break
 		}
-		err := tg.EnableToolset(name)
+		err := tg.enableToolset(name)
 		if err != nil {
 			return err
 		}
@@ -137,7 +153,7 @@ func (tg *ToolsetGroup) EnableToolsets(names []string) error {
 	
 	if tg.everythingOn {
 		for name := range tg.Toolsets {
-			err := tg.EnableToolset(name)
+			err := tg.enableToolset(name)
 			if err != nil {
 				return err
 			}
@@ -149,6 +165,30 @@ func (tg *ToolsetGroup) EnableToolsets(names []string) error {
 
 // EnableToolset enables a specific toolset by name
 func (tg *ToolsetGroup) EnableToolset(name string) error {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+
+	return tg.enableToolset(name)
+}
+
+// TryEnableToolset enables a specific toolset by name and reports whether it was disabled before,
+// so that of several callers enabling it at the same time, only one goes on to register it
+func (tg *ToolsetGroup) TryEnableToolset(name string) (bool, error) {
+	tg.mu.Lock()
+	defer tg.mu.Unlock()
+
+	toolset, exists := tg.Toolsets[name]
+	if !exists {
+		return false, fmt.Errorf("toolset %s does not exist", name)
+	}
+	if toolset.Enabled {
+		return false, nil
+	}
+	return true, tg.enableToolset(name)
+}
+
+// enableToolset enables a specific toolset by name, tg.mu must be held
+func (tg *ToolsetGroup) enableToolset(name string) error {
 	toolset, exists := tg.Toolsets[name]
 	if !exists {
 		return fmt.Errorf("toolset %s does not exist", name)
@@ -160,6 +200,9 @@ func (tg *ToolsetGroup) EnableToolset(name string) error {
 
 // RegisterTools registers all enabled toolsets with the server
 func (tg *ToolsetGroup) RegisterTools(s *server.MCPServer) {
+	tg.mu.RLock()
+	defer tg.mu.RUnlock()
+
 	for _, toolset := range tg.Toolsets {
 		toolset.RegisterTools(s)
 	}